@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -19,11 +20,36 @@ func main() {
 	// Command line flags
 	var (
 		envFile         = flag.String("env", ".env", "Path to environment file")
-		tableName       = flag.String("table", "", "Table name to compare (required)")
+		tableName       = flag.String("table", "", "Table name to compare (required unless -tables is used)")
+		tablesList      = flag.String("tables", "", "Comma-separated tables to compare in one run: names, globs (order_*) or schema-qualified patterns (billing.*); unqualified entries use -schema")
+		excludeTables   = flag.String("exclude-tables", "", "Comma-separated table names or globs left out of -tables (schema.table to restrict to one schema)")
 		schemaName      = flag.String("schema", "public", "Schema name (default: public)")
 		outputFile      = flag.String("output", "", "Output file path (overrides env config)")
 		excludeCols     = flag.String("exclude", "", "Comma-separated list of columns to exclude from comparison")
 		includeCols     = flag.String("include", "", "Comma-separated list of columns to include in comparison (if empty, all columns are used)")
+		keyCols         = flag.String("key", "", "Comma-separated list of business key columns used to pair rows (all other columns are then compared)")
+		where           = flag.String("where", "", "SQL predicate restricting the compared rows on both databases (e.g. \"created_at >= '2024-01-01'\")")
+		whereDB1        = flag.String("where-db1", "", "SQL predicate restricting the rows of DB1 (overrides -where)")
+		whereDB2        = flag.String("where-db2", "", "SQL predicate restricting the rows of DB2 (overrides -where)")
+		db2Schema       = flag.String("db2-schema", "", "Schema of the compared table in DB2, when it differs from -schema")
+		db2Table        = flag.String("db2-table", "", "Name of the compared table in DB2, when it differs from -table")
+		singleConn      = flag.Bool("single-connection", false, "Compare two tables of DB1 over one connection (set -db2-schema and/or -db2-table for the second side; DB2 settings are ignored)")
+		columnMap       = flag.String("column-map", "", "Comma-separated db1_column:db2_column pairs for columns renamed in DB2 (e.g. name:full_name)")
+		caseInsensitive = flag.String("case-insensitive", "", "Comma-separated list of text columns compared case-insensitively ('*' for all columns)")
+		trimColumns     = flag.String("trim", "", "Comma-separated list of text columns compared ignoring leading and trailing whitespace ('*' for all columns)")
+		tolerances      = flag.String("tolerance", "", "Comma-separated column:tolerance pairs, as an amount or a duration (e.g. amount:0.01,synced_at:5s)")
+		nullEquivalents = flag.String("null-equivalent", "", "Comma-separated column:value pairs of values compared equal to NULL, value being empty, zero or false ('*' for all columns, e.g. *:empty,amount:zero)")
+		reportTolerance = flag.Bool("report-within-tolerance", false, "Report differences within tolerance in a separate section instead of ignoring them")
+		jsonKeyOrder    = flag.Bool("json-ignore-key-order", false, "Ignore object key order when comparing json columns")
+		jsonIgnorePaths = flag.String("json-ignore-paths", "", "Comma-separated JSON paths ignored in json/jsonb columns ($.path, or column:$.path for one column; [*] matches any index)")
+		jsonUnordered   = flag.Bool("json-unordered-arrays", false, "Compare JSON arrays as unordered sets")
+		unorderedArrays = flag.String("unordered-arrays", "", "Comma-separated list of array columns compared ignoring element order ('*' for all columns)")
+		probableMatches = flag.Bool("probable-matches", false, "Pair rows found only in one database with similar rows of the other as probable matches")
+		probableThresh  = flag.Float64("probable-match-threshold", 0.6, "Minimum share of equal columns for two unmatched rows to be reported as a probable match")
+		fkDepth         = flag.Int("fk-depth", 1, "Number of foreign key hops followed from the compared table; referenced tables are compared recursively, each row once, stopping at cycles")
+		entity          = flag.String("entity", "", "Compare only the row with these key values (primary key, or the -key columns in order, comma-separated) together with the rows it references and the rows referencing it")
+		aggregate       = flag.Bool("aggregate", false, "Compare each row together with its child rows in the tables referencing its primary key; a row is equal only when its children are equal as a set")
+		childTables     = flag.String("child-tables", "", "Comma-separated child tables compared with -aggregate (table or schema.table, globs allowed; default: every referencing table)")
 		includePK       = flag.Bool("include-pk", false, "Include primary key columns in comparison")
 		excludeFromFile = flag.Bool("exclude-from-file", true, "Exclude columns from file")
 		excludeFile     = flag.String("exclude-file", "exclude_columns.txt", "File containing columns to exclude (one per line)")
@@ -40,6 +66,25 @@ func main() {
 		sourceDB        = flag.String("source-db", "db1", "Source database for script generation: 'db1' or 'db2' (default: db1)")
 		idTarget        = flag.String("id-target", "", "Target ID to be replaced (required with -generate-update-script)")
 		idDestination   = flag.String("id-destination", "", "Destination ID to replace with (required with -generate-update-script)")
+		stream          = flag.Bool("stream", false, "Compare using a streaming sort-merge over server-side cursors (bounded memory, findings written as JSON lines)")
+		streamBatchSize = flag.Int("stream-batch-size", database.DefaultStreamBatchSize, "Rows fetched per cursor round trip in streaming mode")
+		compareSchema   = flag.Bool("compare-schema", false, "Compare the structure of the table (columns, types, nullability, defaults, column order, constraints, indexes, triggers) instead of its data")
+		genMigration    = flag.Bool("generate-migration", false, "Compare the structure of the table and write an SQL migration that brings DB2 in line with DB1 (implies -compare-schema)")
+		allowDestruct   = flag.Bool("allow-destructive", false, "Enable destructive statements (DROP COLUMN, column type changes) in the generated migration instead of commenting them out")
+		compareInv      = flag.Bool("compare-inventory", false, "Compare the object inventory of both databases (tables, views, materialized views, sequences, functions, types, extensions); -table is not needed")
+		inventorySchema = flag.String("inventory-schemas", "", "Comma-separated list of schemas inventoried by -compare-inventory (default: all schemas)")
+		checkSequences  = flag.Bool("check-sequences", false, "Check the sequences of both databases against the max of the column they own and compare their last values; -table is not needed")
+		sequenceSchemas = flag.String("sequence-schemas", "", "Comma-separated list of schemas checked by -check-sequences (default: all schemas)")
+		generateSetval  = flag.Bool("generate-setval", false, "With -check-sequences, write setval statements for the sequences behind their column, one script per database")
+		tableOverview   = flag.Bool("table-overview", false, "List the tables of both databases side by side with row counts, size and last analyze time, highlighting diverging counts; -table is not needed")
+		overviewSchemas = flag.String("overview-schemas", "", "Comma-separated list of schemas listed by -table-overview (default: all schemas)")
+		exactCounts     = flag.Bool("exact-counts", false, "With -table-overview, count rows with count(*) instead of using planner estimates")
+		checksum        = flag.Bool("checksum", false, "Compare per-range checksums computed inside PostgreSQL and fetch only mismatching ranges")
+		checksumChunks  = flag.Int("checksum-chunks", comparator.DefaultChecksumChunks, "Number of initial key ranges in checksum mode")
+		samplePercent   = flag.Float64("sample", 0, "Estimate divergence from a random sample of this percentage of DB1 rows (0 disables sampling)")
+		sampleMethod    = flag.String("sample-method", comparator.DefaultSampleMethod, "Sampling method: 'random', 'bernoulli' or 'system'")
+		sampleConf      = flag.Float64("sample-confidence", comparator.DefaultSampleConfidence, "Confidence level of the estimated mismatch rate interval")
+		checksumLeaf    = flag.Int("checksum-leaf-size", comparator.DefaultChecksumLeafSize, "Maximum rows of a mismatching range fetched for row comparison in checksum mode")
 	)
 	flag.Parse()
 
@@ -76,12 +121,87 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *tableName == "" {
+	// The whole-database modes compare DB1 with DB2; there is no second table of DB1 to read
+	if *singleConn && (*compareInv || *checkSequences || *tableOverview) {
+		log.Fatalf("-single-connection cannot be used with -compare-inventory, -check-sequences or -table-overview")
+	}
+
+	// Handle compare-inventory mode, which covers whole databases instead of one table
+	if *compareInv {
+		handleCompareInventory(*envFile, parseColumnList(*inventorySchema), *outputFile, *verbose)
+		return
+	}
+
+	// Handle check-sequences mode, which covers whole databases instead of one table
+	if *checkSequences {
+		handleCheckSequences(*envFile, parseColumnList(*sequenceSchemas), *outputFile, *verbose, *generateSetval)
+		return
+	}
+
+	// Handle table-overview mode, which covers whole databases instead of one table
+	if *tableOverview {
+		handleTableOverview(*envFile, parseColumnList(*overviewSchemas), *outputFile, *verbose, *maxWorkers, *exactCounts)
+		return
+	}
+
+	batch := *tablesList != ""
+	if batch {
+		if *tableName != "" {
+			fmt.Fprintf(os.Stderr, "Error: -table and -tables cannot be used together\n")
+			os.Exit(1)
+		}
+		if *stream || *compareSchema || *genMigration || *findReferences || *analyzeFKRefs || *generateScript {
+			fmt.Fprintf(os.Stderr, "Error: -tables only supports data comparisons (default, -checksum or -sample)\n")
+			os.Exit(1)
+		}
+		if *keyCols != "" || *includeCols != "" || *columnMap != "" || *db2Table != "" {
+			fmt.Fprintf(os.Stderr, "Error: -key, -include, -column-map and -db2-table name columns or tables of a single table and cannot be used with -tables\n")
+			os.Exit(1)
+		}
+	} else if *tableName == "" {
 		fmt.Fprintf(os.Stderr, "Error: table name is required\n")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if *stream && *checksum {
+		fmt.Fprintf(os.Stderr, "Error: -stream and -checksum cannot be used together\n")
+		os.Exit(1)
+	}
+
+	if *samplePercent != 0 && (*stream || *checksum) {
+		fmt.Fprintf(os.Stderr, "Error: -sample cannot be combined with -stream or -checksum\n")
+		os.Exit(1)
+	}
+
+	if *entity != "" && (batch || *stream || *checksum || *samplePercent != 0 || *compareSchema || *genMigration) {
+		fmt.Fprintf(os.Stderr, "Error: -entity compares one row and cannot be combined with -tables, -stream, -checksum, -sample or -compare-schema\n")
+		os.Exit(1)
+	}
+
+	if *childTables != "" {
+		*aggregate = true
+	}
+
+	if *aggregate && (batch || *stream || *checksum || *samplePercent != 0) {
+		fmt.Fprintf(os.Stderr, "Error: -aggregate compares one parent table and cannot be combined with -tables, -stream, -checksum or -sample\n")
+		os.Exit(1)
+	}
+
+	if *fkDepth < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -fk-depth must be at least 1\n")
+		os.Exit(1)
+	}
+
+	if *genMigration {
+		*compareSchema = true
+	}
+
+	if *compareSchema && (*stream || *checksum || *samplePercent != 0) {
+		fmt.Fprintf(os.Stderr, "Error: -compare-schema cannot be combined with -stream, -checksum or -sample\n")
+		os.Exit(1)
+	}
+
 	// Handle generate-update-script mode
 	if *generateScript {
 		if *sourceDB != "db1" && *sourceDB != "db2" {
@@ -126,10 +246,19 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if *singleConn {
+		cfg.SingleConnection = true
+	}
+
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("Configuration validation failed: %v", err)
 	}
 
+	// Both sides of a single-connection comparison read the same database, so they must name different tables
+	if cfg.SingleConnection && (*db2Schema == "" || *db2Schema == *schemaName) && (*db2Table == "" || *db2Table == *tableName) {
+		log.Fatalf("Single-connection mode requires -db2-schema or -db2-table to name a different table")
+	}
+
 	// Override output file if provided
 	if *outputFile != "" {
 		cfg.OutputFile = *outputFile
@@ -137,7 +266,11 @@ func main() {
 
 	if *verbose {
 		log.Printf("Loaded configuration from %s", *envFile)
-		log.Printf("Comparing table: %s.%s", *schemaName, *tableName)
+		if batch {
+			log.Printf("Comparing tables: %s", *tablesList)
+		} else {
+			log.Printf("Comparing table: %s.%s", *schemaName, *tableName)
+		}
 	}
 
 	// Connect to databases
@@ -147,14 +280,22 @@ func main() {
 	}
 	defer db1.Close()
 
-	db2, err := database.NewConnection(cfg.Database2)
-	if err != nil {
-		log.Fatalf("Failed to connect to database 2: %v", err)
+	// In single-connection mode both sides share the DB1 connection
+	db2 := db1
+	if !cfg.SingleConnection {
+		db2, err = database.NewConnection(cfg.Database2)
+		if err != nil {
+			log.Fatalf("Failed to connect to database 2: %v", err)
+		}
+		defer db2.Close()
 	}
-	defer db2.Close()
 
 	if *verbose {
-		log.Printf("Connected to both databases successfully")
+		if cfg.SingleConnection {
+			log.Printf("Connected in single-connection mode, both sides read %s", cfg.Database1.Database)
+		} else {
+			log.Printf("Connected to both databases successfully")
+		}
 	}
 
 	// Create match criteria
@@ -164,26 +305,59 @@ func main() {
 		ExcludeColumnsFile:     *excludeFile,
 	}
 
-	if *includeCols != "" {
-		criteria.Columns = strings.Split(*includeCols, ",")
-		// Trim whitespace from column names
-		for i, col := range criteria.Columns {
-			criteria.Columns[i] = strings.TrimSpace(col)
-		}
+	criteria.KeyColumns = parseColumnList(*keyCols)
+	criteria.Columns = parseColumnList(*includeCols)
+	criteria.ExcludeColumns = parseColumnList(*excludeCols)
+	criteria.CaseInsensitiveColumns = parseColumnList(*caseInsensitive)
+	criteria.TrimColumns = parseColumnList(*trimColumns)
+	criteria.ReportWithinTolerance = *reportTolerance
+	criteria.JSONIgnoreKeyOrder = *jsonKeyOrder
+	criteria.JSONIgnorePaths = parseColumnList(*jsonIgnorePaths)
+	criteria.JSONUnorderedArrays = *jsonUnordered
+	criteria.UnorderedArrayColumns = parseColumnList(*unorderedArrays)
+	criteria.Tolerances, err = parseColumnPairs(*tolerances, "tolerance")
+	if err != nil {
+		log.Fatalf("Invalid -tolerance: %v", err)
 	}
-
-	if *excludeCols != "" {
-		criteria.ExcludeColumns = strings.Split(*excludeCols, ",")
-		// Trim whitespace from column names
-		for i, col := range criteria.ExcludeColumns {
-			criteria.ExcludeColumns[i] = strings.TrimSpace(col)
-		}
+	criteria.ColumnMap, err = parseColumnPairs(*columnMap, "db2_column")
+	if err != nil {
+		log.Fatalf("Invalid -column-map: %v", err)
+	}
+	criteria.NullEquivalents, err = parseNullEquivalents(*nullEquivalents)
+	if err != nil {
+		log.Fatalf("Invalid -null-equivalent: %v", err)
+	}
+	criteria.FKDepth = *fkDepth
+	criteria.Aggregate = *aggregate
+	criteria.ChildTables = parseColumnList(*childTables)
+	criteria.OptionalColumns = batch
+	criteria.DB2Schema = *db2Schema
+	criteria.DB2Table = *db2Table
+	criteria.WhereDB1 = *where
+	criteria.WhereDB2 = *where
+	if *whereDB1 != "" {
+		criteria.WhereDB1 = *whereDB1
+	}
+	if *whereDB2 != "" {
+		criteria.WhereDB2 = *whereDB2
+	}
+	if *probableMatches {
+		criteria.ProbableMatchThreshold = *probableThresh
 	}
 
 	if *verbose {
 		log.Printf("Comparison settings:")
 		log.Printf("  - Max concurrent workers: %d", *maxWorkers)
 		log.Printf("  - Decode Base64 UUIDs: %v", *decodeUUIDs)
+		if *stream {
+			log.Printf("  - Streaming mode: batch size %d", *streamBatchSize)
+		}
+		if *checksum {
+			log.Printf("  - Checksum mode: %d initial chunks, leaf size %d", *checksumChunks, *checksumLeaf)
+		}
+		if *samplePercent != 0 {
+			log.Printf("  - Sampling mode: %g%% (%s), confidence %g", *samplePercent, *sampleMethod, *sampleConf)
+		}
 		log.Printf("  - Include primary keys: %v", *includePK)
 		log.Printf("  - Exclude columns from file: %v", *excludeFromFile)
 		if *excludeFromFile {
@@ -197,14 +371,130 @@ func main() {
 		if len(criteria.ExcludeColumns) > 0 {
 			log.Printf("  - Additional excluded columns: %v", criteria.ExcludeColumns)
 		}
+		if len(criteria.KeyColumns) > 0 {
+			log.Printf("  - Business key columns: %v", criteria.KeyColumns)
+		}
 		if len(criteria.Columns) > 0 {
 			log.Printf("  - Specific columns to include: %v", criteria.Columns)
 		}
+		if len(criteria.CaseInsensitiveColumns) > 0 {
+			log.Printf("  - Case-insensitive columns: %v", criteria.CaseInsensitiveColumns)
+		}
+		if len(criteria.TrimColumns) > 0 {
+			log.Printf("  - Whitespace-trimmed columns: %v", criteria.TrimColumns)
+		}
+		if len(criteria.Tolerances) > 0 {
+			log.Printf("  - Column tolerances: %v", criteria.Tolerances)
+		}
+		if len(criteria.NullEquivalents) > 0 {
+			log.Printf("  - NULL equivalents: %v", criteria.NullEquivalents)
+		}
+		if len(criteria.UnorderedArrayColumns) > 0 {
+			log.Printf("  - Unordered array columns: %v", criteria.UnorderedArrayColumns)
+		}
+		if len(criteria.JSONIgnorePaths) > 0 {
+			log.Printf("  - Ignored JSON paths: %v", criteria.JSONIgnorePaths)
+		}
+		if criteria.DB2Schema != "" || criteria.DB2Table != "" {
+			_, target2 := criteria.Targets(*schemaName, *tableName)
+			log.Printf("  - DB2 table: %s.%s", target2.Schema, target2.Table)
+		}
+		if len(criteria.ColumnMap) > 0 {
+			log.Printf("  - DB2 column map: %v", criteria.ColumnMap)
+		}
+		if criteria.WhereDB1 != "" {
+			log.Printf("  - DB1 row filter: %s", criteria.WhereDB1)
+		}
+		if criteria.WhereDB2 != "" {
+			log.Printf("  - DB2 row filter: %s", criteria.WhereDB2)
+		}
+		if criteria.Aggregate {
+			if len(criteria.ChildTables) > 0 {
+				log.Printf("  - Aggregate comparison with child tables: %v", criteria.ChildTables)
+			} else {
+				log.Printf("  - Aggregate comparison with every referencing table")
+			}
+		}
+		if criteria.FKDepth > 1 {
+			log.Printf("  - Foreign key depth: %d", criteria.FKDepth)
+		}
+		if criteria.ProbableMatchThreshold > 0 {
+			log.Printf("  - Probable match threshold: %g", criteria.ProbableMatchThreshold)
+		}
 	}
 
 	// Create comparator with concurrent support and UUID decoding
 	comp := comparator.NewComparatorWithUUIDDecoding(db1, db2, *maxWorkers, *decodeUUIDs)
-	result, err := comp.CompareTable(*schemaName, *tableName, criteria)
+
+	if batch {
+		compare := comparator.TableComparison(comp.CompareTable)
+		if *checksum {
+			compare = func(schema, table string, criteria *models.MatchCriteria) (*models.ComparisonResult, error) {
+				return comp.CompareTableChunked(schema, table, criteria, *checksumChunks, *checksumLeaf)
+			}
+		} else if *samplePercent != 0 {
+			compare = func(schema, table string, criteria *models.MatchCriteria) (*models.ComparisonResult, error) {
+				return comp.CompareTableSampled(schema, table, criteria, *sampleMethod, *samplePercent, *sampleConf)
+			}
+		}
+
+		// The batch report writes to its own file unless -output was given
+		if *outputFile == "" {
+			cfg.OutputFile = "batch_comparison.json"
+		}
+
+		runBatchComparison(comp, compare, *tablesList, *excludeTables, *schemaName, criteria, cfg.OutputFile, cfg.OutputFormat)
+		return
+	}
+
+	if *compareSchema {
+		// Structural comparison writes to its own file unless -output was given
+		if *outputFile == "" {
+			cfg.OutputFile = "schema_comparison.json"
+		}
+
+		schemaResult, err := comp.CompareTableSchema(*schemaName, *tableName, criteria)
+		if err != nil {
+			log.Fatalf("Failed to compare table schema: %v", err)
+		}
+		if err := outputResults(schemaResult, cfg.OutputFile, cfg.OutputFormat); err != nil {
+			log.Fatalf("Failed to output results: %v", err)
+		}
+		printSchemaSummary(schemaResult)
+
+		if *genMigration {
+			writeMigrationScript(schemaResult, *outputFile, *allowDestruct)
+		}
+		return
+	}
+
+	if *entity != "" {
+		// The entity report writes to its own file unless -output was given
+		if *outputFile == "" {
+			cfg.OutputFile = "entity_comparison.json"
+		}
+
+		entityResult, err := comp.CompareEntity(*schemaName, *tableName, parseColumnList(*entity), criteria)
+		if err != nil {
+			log.Fatalf("Failed to compare entity: %v", err)
+		}
+		if err := outputResults(entityResult, cfg.OutputFile, cfg.OutputFormat); err != nil {
+			log.Fatalf("Failed to output results: %v", err)
+		}
+		printEntitySummary(entityResult)
+		return
+	}
+
+	var result *models.ComparisonResult
+	if *stream {
+		result, err = runStreamingComparison(comp, *schemaName, *tableName, criteria, *streamBatchSize, cfg.OutputFile)
+	} else if *checksum {
+		result, err = comp.CompareTableChunked(*schemaName, *tableName, criteria, *checksumChunks, *checksumLeaf)
+	} else if *samplePercent != 0 {
+		result, err = comp.CompareTableSampled(*schemaName, *tableName, criteria, *sampleMethod, *samplePercent, *sampleConf)
+	} else {
+		result, err = comp.CompareTable(*schemaName, *tableName, criteria)
+	}
 	if err != nil {
 		log.Fatalf("Failed to compare table: %v", err)
 	}
@@ -225,6 +515,91 @@ func main() {
 	printSummary(result)
 }
 
+// runBatchComparison resolves the -tables patterns and compares every table with the selected comparison mode
+func runBatchComparison(comp *comparator.Comparator, compare comparator.TableComparison, tablesList, excludeTables, schemaName string,
+	criteria *models.MatchCriteria, outputFile, format string) {
+	tables, err := comp.ResolveTables(parseColumnList(tablesList), parseColumnList(excludeTables), schemaName)
+	if err != nil {
+		log.Fatalf("Failed to resolve tables: %v", err)
+	}
+	fmt.Printf("Comparing %d tables, %d at a time\n", len(tables), comp.MaxWorkers)
+
+	result := comp.CompareTables(tables, criteria, compare)
+
+	if err := outputResults(result, outputFile, format); err != nil {
+		log.Fatalf("Failed to output results: %v", err)
+	}
+
+	printBatchSummary(result)
+}
+
+// printBatchSummary prints the per-table summary of a batch comparison to console
+func printBatchSummary(result *models.BatchComparisonResult) {
+	fmt.Printf("\n=== BATCH COMPARISON SUMMARY ===\n")
+	fmt.Printf("Tables: %d (identical: %d, different: %d, failed: %d)\n",
+		result.TotalTables, result.IdenticalTables, result.DifferentTables, result.FailedTables)
+	fmt.Printf("Duration: %dms\n", result.DurationMs)
+
+	if result.DifferentTables > 0 {
+		fmt.Printf("\n--- Tables With Differences ---\n")
+		for _, summary := range result.Summaries {
+			if summary.Status == models.BatchStatusDifferent {
+				fmt.Printf("%s.%s: %d differences, %d only in DB1, %d only in DB2\n", summary.Schema, summary.TableName,
+					summary.Differences, summary.OnlyInDB1, summary.OnlyInDB2)
+			}
+		}
+	}
+
+	if result.FailedTables > 0 {
+		fmt.Printf("\n--- Failed Tables ---\n")
+		for _, summary := range result.Summaries {
+			if summary.Status == models.BatchStatusFailed {
+				fmt.Printf("%s.%s: %s\n", summary.Schema, summary.TableName, summary.Error)
+			}
+		}
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// parseColumnList splits a comma-separated list of column names, trimming whitespace and dropping empty entries
+func parseColumnList(value string) []string {
+	var columns []string
+	for _, col := range strings.Split(value, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// parseColumnPairs parses comma-separated column:value pairs; valueName names the value in errors
+func parseColumnPairs(value, valueName string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range parseColumnList(value) {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("expected column:%s, got %q", valueName, pair)
+		}
+		pairs[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return pairs, nil
+}
+
+// parseNullEquivalents parses comma-separated column:value pairs; a column may be listed more than once
+func parseNullEquivalents(value string) (map[string][]string, error) {
+	equivalents := make(map[string][]string)
+	for _, pair := range parseColumnList(value) {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("expected column:value, got %q", pair)
+		}
+		col := strings.TrimSpace(parts[0])
+		equivalents[col] = append(equivalents[col], strings.TrimSpace(parts[1]))
+	}
+	return equivalents, nil
+}
+
 // ensureGeneratedPath creates the generated directory if it doesn't exist and returns the full path
 func ensureGeneratedPath(filename string) (string, error) {
 	generatedDir := "generated"
@@ -247,8 +622,62 @@ func ensureGeneratedPath(filename string) (string, error) {
 	return fmt.Sprintf("%s/%s", generatedDir, filename), nil
 }
 
+// jsonLinesSink writes streaming comparison findings as one JSON document per line
+type jsonLinesSink struct {
+	encoder *json.Encoder
+}
+
+func (s *jsonLinesSink) OnlyInDB1(row models.TableRow) error {
+	return s.encoder.Encode(models.StreamEvent{Type: models.StreamEventOnlyInDB1, Row: row})
+}
+
+func (s *jsonLinesSink) OnlyInDB2(row models.TableRow) error {
+	return s.encoder.Encode(models.StreamEvent{Type: models.StreamEventOnlyInDB2, Row: row})
+}
+
+func (s *jsonLinesSink) Difference(diff models.RowDifference) error {
+	return s.encoder.Encode(models.StreamEvent{Type: models.StreamEventDifference, Difference: &diff})
+}
+
+func (s *jsonLinesSink) DuplicateKey(dup models.DuplicateKeyDifference) error {
+	return s.encoder.Encode(models.StreamEvent{Type: models.StreamEventDuplicate, Duplicate: &dup})
+}
+
+// runStreamingComparison runs a streaming comparison writing its findings to a .jsonl file next to the output file
+func runStreamingComparison(comp *comparator.Comparator, schemaName, tableName string, criteria *models.MatchCriteria, batchSize int, outputFile string) (*models.ComparisonResult, error) {
+	eventsFile := "comparison_result.jsonl"
+	if outputFile != "" {
+		eventsFile = strings.TrimSuffix(outputFile, ".json") + ".jsonl"
+	}
+
+	eventsPath, err := ensureGeneratedPath(eventsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare events path: %w", err)
+	}
+
+	file, err := os.Create(eventsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create events file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	result, err := comp.CompareTableStreaming(schemaName, tableName, criteria, batchSize, &jsonLinesSink{encoder: json.NewEncoder(writer)})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write events file: %w", err)
+	}
+
+	result.Streaming.EventsFile = eventsPath
+	fmt.Printf("Streaming findings written to: %s\n", eventsPath)
+	return result, nil
+}
+
 // outputResults writes the comparison results to a file
-func outputResults(result *models.ComparisonResult, outputFile, format string) error {
+func outputResults(result interface{}, outputFile, format string) error {
 	var data []byte
 	var err error
 
@@ -286,14 +715,60 @@ func outputResults(result *models.ComparisonResult, outputFile, format string) e
 func printSummary(result *models.ComparisonResult) {
 	fmt.Printf("\n=== COMPARISON SUMMARY ===\n")
 	fmt.Printf("Table: %s.%s\n", result.Schema, result.TableName)
+	if result.DB2Schema != "" || result.DB2TableName != "" {
+		db2Schema, db2Table := result.Schema, result.TableName
+		if result.DB2Schema != "" {
+			db2Schema = result.DB2Schema
+		}
+		if result.DB2TableName != "" {
+			db2Table = result.DB2TableName
+		}
+		fmt.Printf("DB2 table: %s.%s\n", db2Schema, db2Table)
+	}
+	if len(result.ColumnMap) > 0 {
+		fmt.Printf("DB2 column map: %v\n", result.ColumnMap)
+	}
+	if len(result.ChildTables) > 0 {
+		fmt.Printf("Child tables: %s\n", strings.Join(result.ChildTables, ", "))
+	}
 	fmt.Printf("Timestamp: %s\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+	if result.WhereDB1 != "" {
+		fmt.Printf("DB1 filter: %s\n", result.WhereDB1)
+	}
+	if result.WhereDB2 != "" {
+		fmt.Printf("DB2 filter: %s\n", result.WhereDB2)
+	}
 	fmt.Printf("\n--- Row Counts ---\n")
 	fmt.Printf("Database 1: %d rows\n", result.TotalRowsDB1)
 	fmt.Printf("Database 2: %d rows\n", result.TotalRowsDB2)
 	fmt.Printf("Matched: %d rows\n", result.MatchedRows)
-	fmt.Printf("Only in DB1: %d rows\n", len(result.OnlyInDB1))
-	fmt.Printf("Only in DB2: %d rows\n", len(result.OnlyInDB2))
-	fmt.Printf("Rows with differences: %d\n", len(result.Differences))
+	if result.Streaming != nil {
+		fmt.Printf("Only in DB1: %d rows\n", result.Streaming.OnlyInDB1)
+		fmt.Printf("Only in DB2: %d rows\n", result.Streaming.OnlyInDB2)
+		fmt.Printf("Rows with differences: %d\n", result.Streaming.Differences)
+		fmt.Printf("Keys with differing duplicates: %d\n", result.Streaming.DuplicateKeys)
+		if result.Streaming.WithinTolerance > 0 {
+			fmt.Printf("Rows differing only within tolerance: %d\n", result.Streaming.WithinTolerance)
+		}
+		if result.Streaming.NullEquivalent > 0 {
+			fmt.Printf("Rows differing only by NULL equivalents: %d\n", result.Streaming.NullEquivalent)
+		}
+		fmt.Printf("Findings file: %s\n", result.Streaming.EventsFile)
+	} else {
+		fmt.Printf("Only in DB1: %d rows\n", len(result.OnlyInDB1))
+		fmt.Printf("Only in DB2: %d rows\n", len(result.OnlyInDB2))
+		fmt.Printf("Rows with differences: %d\n", len(result.Differences))
+		fmt.Printf("Keys with differing duplicates: %d\n", len(result.DuplicateKeys))
+		if len(result.WithinTolerance) > 0 {
+			fmt.Printf("Rows differing only within tolerance: %d\n", len(result.WithinTolerance))
+		}
+		if result.NullEquivalentRows > 0 {
+			fmt.Printf("Rows differing only by NULL equivalents: %d\n", result.NullEquivalentRows)
+		}
+		if len(result.ProbableMatches) > 0 {
+			fmt.Printf("Probable matches: %d\n", len(result.ProbableMatches))
+		}
+	}
 
 	if len(result.Differences) > 0 {
 		fmt.Printf("\n--- Sample Differences ---\n")
@@ -308,34 +783,583 @@ func printSummary(result *models.ComparisonResult) {
 					fmt.Printf("  ... and %d more column differences\n", len(diff.ColumnDifferences)-2)
 					break
 				}
+				if len(colDiff.JSONDiff) > 0 {
+					paths := make([]string, len(colDiff.JSONDiff))
+					for k, pathDiff := range colDiff.JSONDiff {
+						paths[k] = fmt.Sprintf("%s (%s)", pathDiff.Path, pathDiff.Change)
+					}
+					fmt.Printf("  Column '%s': JSON paths %s\n", colDiff.ColumnName, strings.Join(paths, ", "))
+					continue
+				}
+				if colDiff.ArrayDiff != nil {
+					fmt.Printf("  Column '%s': added %v, removed %v", colDiff.ColumnName, colDiff.ArrayDiff.Added, colDiff.ArrayDiff.Removed)
+					if colDiff.ArrayDiff.OrderChanged {
+						fmt.Printf(" (order changed)")
+					}
+					fmt.Printf("\n")
+					continue
+				}
 				fmt.Printf("  Column '%s': DB1='%v' vs DB2='%v'\n", colDiff.ColumnName, colDiff.DB1Value, colDiff.DB2Value)
 			}
+			for _, child := range diff.ChildDifferences {
+				fmt.Printf("  Children %s.%s: %d only in DB1, %d only in DB2, %d changed\n",
+					child.Schema, child.TableName, len(child.OnlyInDB1), len(child.OnlyInDB2), len(child.Differences))
+			}
+		}
+	}
+
+	if result.Checksum != nil {
+		fmt.Printf("\n--- Checksum Ranges ---\n")
+		fmt.Printf("Key columns: %s\n", strings.Join(result.Checksum.KeyColumns, ", "))
+		fmt.Printf("Ranges compared: %d (%d matching)\n", result.Checksum.RangesCompared, result.Checksum.MatchingRanges)
+		fmt.Printf("Mismatching leaf ranges fetched: %d\n", result.Checksum.MismatchedLeaves)
+		fmt.Printf("Rows fetched: DB1=%d, DB2=%d\n", result.Checksum.RowsFetchedDB1, result.Checksum.RowsFetchedDB2)
+	}
+
+	if len(result.ProbableMatches) > 0 {
+		fmt.Printf("\n--- Probable Matches ---\n")
+		for i, match := range result.ProbableMatches {
+			if i >= 3 { // Show only first 3 probable matches in summary
+				fmt.Printf("... and %d more probable matches\n", len(result.ProbableMatches)-3)
+				break
+			}
+			columns := make([]string, len(match.ColumnDifferences))
+			for j, colDiff := range match.ColumnDifferences {
+				columns[j] = colDiff.ColumnName
+			}
+			fmt.Printf("Row %s: %.0f%% similar, differs in %s\n", match.RowIdentifier, match.Confidence*100, strings.Join(columns, ", "))
+		}
+	}
+
+	if result.Sampling != nil {
+		s := result.Sampling
+		fmt.Printf("\n--- Sampling Estimate ---\n")
+		fmt.Printf("Sample: %g%% (%s) on %s, %d rows", s.Percent, s.Method, strings.Join(s.KeyColumns, ", "), s.SampledRows)
+		if s.SkippedNullKeys > 0 {
+			fmt.Printf(" (%d skipped with NULL keys)", s.SkippedNullKeys)
+		}
+		fmt.Printf("\n")
+		fmt.Printf("Missing in DB2: %d, with differences: %d\n", s.MissingInDB2, s.RowsWithDifferences)
+		fmt.Printf("Estimated mismatch rate: %.2f%% (%.0f%% CI: %.2f%% - %.2f%%)\n",
+			s.MismatchRate*100, s.ConfidenceLevel*100, s.ConfidenceLow*100, s.ConfidenceHigh*100)
+		if s.EstimatedTableRows > 0 {
+			fmt.Printf("Estimated mismatching rows: ~%d of ~%d\n", s.EstimatedMismatchedRows, s.EstimatedTableRows)
+		}
+	}
+
+	if len(result.DuplicateKeys) > 0 {
+		fmt.Printf("\n--- Duplicate Keys ---\n")
+		for i, dup := range result.DuplicateKeys {
+			if i >= 3 { // Show only first 3 duplicate keys in summary
+				fmt.Printf("... and %d more duplicate keys\n", len(result.DuplicateKeys)-3)
+				break
+			}
+			fmt.Printf("Key %s: DB1=%d occurrences vs DB2=%d occurrences\n", dup.Key, dup.CountDB1, dup.CountDB2)
 		}
 	}
 
 	if len(result.ForeignKeyResults) > 0 {
 		fmt.Printf("\n--- Foreign Key Analysis ---\n")
-		for _, fkResult := range result.ForeignKeyResults {
-			if fkResult.Error != "" {
-				fmt.Printf("FK %s -> %s.%s: ERROR - %s\n",
-					fkResult.ForeignKey.ColumnName,
-					fkResult.ForeignKey.ReferencedSchema,
-					fkResult.ForeignKey.ReferencedTable,
-					fkResult.Error)
+		printForeignKeyResults(result.ForeignKeyResults, "")
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// printForeignKeyResults prints foreign key comparisons, indenting the ones followed from a referenced table
+func printForeignKeyResults(fkResults []models.ForeignKeyResult, indent string) {
+	for _, fkResult := range fkResults {
+		if fkResult.Error != "" {
+			fmt.Printf("%sFK %s -> %s.%s: ERROR - %s\n",
+				indent,
+				fkResult.ForeignKey.ColumnName,
+				fkResult.ForeignKey.ReferencedSchema,
+				fkResult.ForeignKey.ReferencedTable,
+				fkResult.Error)
+			continue
+		}
+
+		fmt.Printf("%sFK %s -> %s.%s: %d matched, %d differences",
+			indent,
+			fkResult.ForeignKey.ColumnName,
+			fkResult.ForeignKey.ReferencedSchema,
+			fkResult.ForeignKey.ReferencedTable,
+			fkResult.ComparisonResult.MatchedRows,
+			len(fkResult.ComparisonResult.Differences))
+		if fkResult.NestedElsewhere {
+			fmt.Printf(" (some rows compared above)")
+		}
+		if fkResult.CycleDetected {
+			fmt.Printf(" (cycle, not followed further)")
+		}
+		fmt.Printf("\n")
+
+		printForeignKeyResults(fkResult.Nested, indent+"  ")
+	}
+}
+
+// printEntitySummary prints a summary of the comparison of a single entity to console
+func printEntitySummary(result *models.EntityComparisonResult) {
+	fmt.Printf("\n=== ENTITY COMPARISON SUMMARY ===\n")
+	fmt.Printf("Table: %s.%s\n", result.Schema, result.TableName)
+	if result.DB2Schema != "" || result.DB2TableName != "" {
+		db2Schema, db2Table := result.Schema, result.TableName
+		if result.DB2Schema != "" {
+			db2Schema = result.DB2Schema
+		}
+		if result.DB2TableName != "" {
+			db2Table = result.DB2TableName
+		}
+		fmt.Printf("DB2 table: %s.%s\n", db2Schema, db2Table)
+	}
+	fmt.Printf("Key: %s = %s\n", strings.Join(result.KeyColumns, ", "), strings.Join(result.KeyValues, ", "))
+
+	fmt.Printf("\n--- Row ---\n")
+	switch result.Status {
+	case models.EntityStatusOnlyInDB1:
+		fmt.Printf("Only in DB1\n")
+	case models.EntityStatusOnlyInDB2:
+		fmt.Printf("Only in DB2\n")
+	case models.EntityStatusIdentical:
+		fmt.Printf("Identical in both databases\n")
+	default:
+		fmt.Printf("%d column differences\n", len(result.Difference.ColumnDifferences))
+		for _, colDiff := range result.Difference.ColumnDifferences {
+			fmt.Printf("  Column '%s': DB1='%v' vs DB2='%v'\n", colDiff.ColumnName, colDiff.DB1Value, colDiff.DB2Value)
+		}
+	}
+
+	if len(result.Outgoing) > 0 {
+		fmt.Printf("\n--- Referenced Rows ---\n")
+		printForeignKeyResults(result.Outgoing, "")
+	}
+
+	if len(result.Incoming) > 0 {
+		fmt.Printf("\n--- Referencing Rows ---\n")
+		for _, child := range result.Incoming {
+			fmt.Printf("%s.%s.%s: DB1=%d rows, DB2=%d rows, %d only in DB1, %d only in DB2, %d changed\n",
+				child.Schema, child.TableName, child.ColumnName, child.TotalRowsDB1, child.TotalRowsDB2,
+				len(child.OnlyInDB1), len(child.OnlyInDB2), len(child.Differences))
+		}
+	}
+
+	if !result.HasDifferences() {
+		fmt.Printf("\nThe entity and its neighbouring rows are identical\n")
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// printSchemaSummary prints a summary of the schema comparison to console
+func printSchemaSummary(result *models.SchemaComparisonResult) {
+	fmt.Printf("\n=== SCHEMA COMPARISON SUMMARY ===\n")
+	fmt.Printf("Table: %s.%s\n", result.Schema, result.TableName)
+	if result.DB2Schema != "" || result.DB2TableName != "" {
+		target2 := result.DB2Target()
+		fmt.Printf("DB2 table: %s.%s\n", target2.Schema, target2.Table)
+	}
+
+	if !result.HasDifferences() {
+		fmt.Printf("\nBoth databases have the same table structure\n")
+		fmt.Printf("\n=========================\n")
+		return
+	}
+
+	if len(result.OnlyInDB1) > 0 {
+		fmt.Printf("\n--- Columns Only in DB1 ---\n")
+		for _, col := range result.OnlyInDB1 {
+			fmt.Printf("%s %s\n", col.ColumnName, col.FormattedType)
+		}
+	}
+
+	if len(result.OnlyInDB2) > 0 {
+		fmt.Printf("\n--- Columns Only in DB2 ---\n")
+		for _, col := range result.OnlyInDB2 {
+			fmt.Printf("%s %s\n", col.ColumnName, col.FormattedType)
+		}
+	}
+
+	if len(result.ColumnDifferences) > 0 {
+		fmt.Printf("\n--- Column Differences ---\n")
+		for _, diff := range result.ColumnDifferences {
+			fmt.Printf("%s.%s: DB1=%q vs DB2=%q\n", diff.ColumnName, diff.Attribute, diff.DB1Value, diff.DB2Value)
+		}
+	}
+
+	if result.ColumnOrderDiffers {
+		fmt.Printf("\n--- Column Order ---\n")
+		fmt.Printf("DB1: %s\n", strings.Join(result.ColumnOrderDB1, ", "))
+		fmt.Printf("DB2: %s\n", strings.Join(result.ColumnOrderDB2, ", "))
+	}
+
+	if len(result.ObjectDifferences) > 0 {
+		fmt.Printf("\n--- Constraint, Index and Trigger Differences ---\n")
+		for _, diff := range result.ObjectDifferences {
+			fmt.Printf("%s %s: %s\n", diff.ObjectType, diff.Name, diff.Change)
+			if diff.DB1Definition != "" {
+				fmt.Printf("  DB1: %s\n", objectDescription(diff.DB1Kind, diff.DB1Definition))
+			}
+			if diff.DB2Definition != "" {
+				fmt.Printf("  DB2: %s\n", objectDescription(diff.DB2Kind, diff.DB2Definition))
+			}
+		}
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// writeMigrationScript writes the SQL migration of a schema comparison to the generated directory
+func writeMigrationScript(result *models.SchemaComparisonResult, outputFile string, allowDestructive bool) {
+	if !result.HasDifferences() {
+		fmt.Printf("No migration needed: both databases have the same table structure\n")
+		return
+	}
+
+	script := comparator.GenerateMigrationScript(result, allowDestructive)
+
+	// The script is named after -output when given, with a .sql extension
+	scriptFile := "schema_migration.sql"
+	if outputFile != "" {
+		scriptFile = strings.TrimSuffix(outputFile, ".json") + "_migration.sql"
+	}
+
+	scriptPath, err := ensureGeneratedPath(scriptFile)
+	if err != nil {
+		log.Fatalf("Failed to prepare script path: %v", err)
+	}
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		log.Fatalf("Failed to write script to file %s: %v", scriptPath, err)
+	}
+
+	target2 := result.DB2Target()
+	fmt.Printf("\nMigration script written to: %s\n", scriptPath)
+	if !allowDestructive {
+		fmt.Printf("Destructive statements are commented out; use -allow-destructive to enable them\n")
+	}
+	fmt.Printf("⚠️  Review the script, then run it against DB2 (%s.%s): psql -d <database> -f %s\n", target2.Schema, target2.Table, scriptPath)
+}
+
+// objectDescription formats the definition of a table object, prefixed by its kind when it has one
+func objectDescription(kind, definition string) string {
+	if kind == "" {
+		return definition
+	}
+	return fmt.Sprintf("[%s] %s", kind, definition)
+}
+
+// connectDatabases loads the configuration and connects to both databases for a whole-database
+// mode, exiting on failure. Such modes need two databases, so single-connection mode is rejected.
+func connectDatabases(envFile, mode string, verbose bool) (*config.Config, *database.Connection, *database.Connection) {
+	cfg, err := config.LoadConfig(envFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.SingleConnection {
+		log.Fatalf("%s compares two databases and cannot run with SINGLE_CONNECTION=true", mode)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	db1, err := database.NewConnection(cfg.Database1)
+	if err != nil {
+		log.Fatalf("Failed to connect to database 1: %v", err)
+	}
+
+	db2, err := database.NewConnection(cfg.Database2)
+	if err != nil {
+		db1.Close()
+		log.Fatalf("Failed to connect to database 2: %v", err)
+	}
+
+	if verbose {
+		log.Printf("Connected to both databases successfully")
+	}
+
+	return cfg, db1, db2
+}
+
+// handleCompareInventory handles the compare-inventory mode
+func handleCompareInventory(envFile string, schemas []string, outputFile string, verbose bool) {
+	if verbose {
+		if len(schemas) > 0 {
+			log.Printf("Comparing object inventory of schemas %v", schemas)
+		} else {
+			log.Printf("Comparing object inventory of all schemas")
+		}
+	}
+
+	cfg, db1, db2 := connectDatabases(envFile, "-compare-inventory", verbose)
+	defer db1.Close()
+	defer db2.Close()
+
+	comp := comparator.NewComparator(db1, db2)
+
+	result, err := comp.CompareInventory(schemas)
+	if err != nil {
+		log.Fatalf("Failed to compare object inventory: %v", err)
+	}
+
+	// The inventory writes to its own file unless -output was given
+	outputFileName := "inventory_comparison.json"
+	if outputFile != "" {
+		outputFileName = outputFile
+	}
+	if err := outputResults(result, outputFileName, cfg.OutputFormat); err != nil {
+		log.Fatalf("Failed to output results: %v", err)
+	}
+
+	printInventorySummary(result)
+}
+
+// printInventorySummary prints a summary of the object inventory comparison to console
+func printInventorySummary(result *models.InventoryComparisonResult) {
+	fmt.Printf("\n=== OBJECT INVENTORY SUMMARY ===\n")
+	if len(result.Schemas) > 0 {
+		fmt.Printf("Schemas: %s\n", strings.Join(result.Schemas, ", "))
+	} else {
+		fmt.Printf("Schemas: all\n")
+	}
+
+	fmt.Printf("\n--- Object Counts (DB1 / DB2) ---\n")
+	for _, count := range result.Counts {
+		marker := ""
+		if count.DB1 != count.DB2 {
+			marker = " *"
+		}
+		fmt.Printf("%-18s %6d / %-6d%s\n", count.ObjectType+":", count.DB1, count.DB2, marker)
+	}
+
+	if !result.HasDifferences() {
+		fmt.Printf("\nBoth databases have the same objects and definitions\n")
+		fmt.Printf("\n=========================\n")
+		return
+	}
+
+	if len(result.OnlyInDB1) > 0 {
+		fmt.Printf("\n--- Only in DB1 (%d) ---\n", len(result.OnlyInDB1))
+		for _, object := range result.OnlyInDB1 {
+			fmt.Printf("%s %s.%s\n", object.ObjectType, object.Schema, object.Name)
+		}
+	}
+
+	if len(result.OnlyInDB2) > 0 {
+		fmt.Printf("\n--- Only in DB2 (%d) ---\n", len(result.OnlyInDB2))
+		for _, object := range result.OnlyInDB2 {
+			fmt.Printf("%s %s.%s\n", object.ObjectType, object.Schema, object.Name)
+		}
+	}
+
+	if len(result.DefinitionDifferences) > 0 {
+		fmt.Printf("\n--- Different Definitions (%d) ---\n", len(result.DefinitionDifferences))
+		for _, diff := range result.DefinitionDifferences {
+			if diff.DB1Version != "" || diff.DB2Version != "" {
+				fmt.Printf("%s %s.%s: version %s vs %s\n", diff.ObjectType, diff.Schema, diff.Name, diff.DB1Version, diff.DB2Version)
 			} else {
-				fmt.Printf("FK %s -> %s.%s: %d matched, %d differences\n",
-					fkResult.ForeignKey.ColumnName,
-					fkResult.ForeignKey.ReferencedSchema,
-					fkResult.ForeignKey.ReferencedTable,
-					fkResult.ComparisonResult.MatchedRows,
-					len(fkResult.ComparisonResult.Differences))
+				fmt.Printf("%s %s.%s\n", diff.ObjectType, diff.Schema, diff.Name)
+			}
+		}
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// handleCheckSequences handles the check-sequences mode
+func handleCheckSequences(envFile string, schemas []string, outputFile string, verbose, generateSetval bool) {
+	if verbose {
+		if len(schemas) > 0 {
+			log.Printf("Checking sequences of schemas %v", schemas)
+		} else {
+			log.Printf("Checking sequences of all schemas")
+		}
+	}
+
+	cfg, db1, db2 := connectDatabases(envFile, "-check-sequences", verbose)
+	defer db1.Close()
+	defer db2.Close()
+
+	comp := comparator.NewComparator(db1, db2)
+
+	result, err := comp.CheckSequences(schemas)
+	if err != nil {
+		log.Fatalf("Failed to check sequences: %v", err)
+	}
+
+	// The check writes to its own file unless -output was given
+	outputFileName := "sequence_health.json"
+	if outputFile != "" {
+		outputFileName = outputFile
+	}
+	if err := outputResults(result, outputFileName, cfg.OutputFormat); err != nil {
+		log.Fatalf("Failed to output results: %v", err)
+	}
+
+	printSequenceSummary(result)
+
+	if !generateSetval {
+		return
+	}
+
+	for _, db := range []string{"db1", "db2"} {
+		script := comparator.GenerateSetvalScript(result, db)
+		scriptPath, err := ensureGeneratedPath(fmt.Sprintf("sequence_setval_%s.sql", db))
+		if err != nil {
+			log.Fatalf("Failed to prepare script path: %v", err)
+		}
+		if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+			log.Fatalf("Failed to write script to file %s: %v", scriptPath, err)
+		}
+		fmt.Printf("setval script for %s written to: %s\n", strings.ToUpper(db), scriptPath)
+	}
+	fmt.Printf("⚠️  Review each script and run it only against its own database\n")
+}
+
+// printSequenceSummary prints a summary of the sequence health check to console
+func printSequenceSummary(result *models.SequenceHealthResult) {
+	fmt.Printf("\n=== SEQUENCE HEALTH SUMMARY ===\n")
+	fmt.Printf("Sequences checked: %d\n", result.TotalSequences)
+	fmt.Printf("At risk: %d\n", result.AtRiskSequences)
+	fmt.Printf("Different last values: %d\n", result.DifferingValues)
+
+	if result.AtRiskSequences > 0 {
+		fmt.Printf("\n--- At Risk ---\n")
+		for _, seq := range result.Sequences {
+			if !seq.AtRisk() {
+				continue
+			}
+			owner := ""
+			if seq.TableName != "" {
+				owner = fmt.Sprintf(" (%s.%s)", seq.TableName, seq.ColumnName)
+			}
+			fmt.Printf("%s.%s%s\n", seq.Schema, seq.SequenceName, owner)
+			for _, side := range []struct {
+				name  string
+				state *models.SequenceState
+			}{{"DB1", seq.DB1}, {"DB2", seq.DB2}} {
+				if side.state == nil || len(side.state.Issues) == 0 {
+					continue
+				}
+				bound := "-"
+				if side.state.ColumnBound != nil {
+					bound = fmt.Sprintf("%d", *side.state.ColumnBound)
+				}
+				fmt.Printf("  %s: next value %d, column bound %s, %.1f%% used [%s]\n",
+					side.name, side.state.NextValue, bound, side.state.UsedPercent, strings.Join(side.state.Issues, ", "))
 			}
 		}
 	}
 
+	var missing []string
+	for _, seq := range result.Sequences {
+		if seq.DB1 == nil {
+			missing = append(missing, fmt.Sprintf("%s.%s (only in DB2)", seq.Schema, seq.SequenceName))
+		} else if seq.DB2 == nil {
+			missing = append(missing, fmt.Sprintf("%s.%s (only in DB1)", seq.Schema, seq.SequenceName))
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("\n--- Missing Sequences ---\n")
+		for _, name := range missing {
+			fmt.Printf("%s\n", name)
+		}
+	}
+
+	if result.DifferingValues > 0 {
+		fmt.Printf("\n--- Different Next Values ---\n")
+		for _, seq := range result.Sequences {
+			if seq.Differs {
+				fmt.Printf("%s.%s: DB1=%d vs DB2=%d\n", seq.Schema, seq.SequenceName, seq.DB1.NextValue, seq.DB2.NextValue)
+			}
+		}
+	}
+
+	fmt.Printf("\n=========================\n")
+}
+
+// handleTableOverview handles the table-overview mode
+func handleTableOverview(envFile string, schemas []string, outputFile string, verbose bool, maxWorkers int, exactCounts bool) {
+	if verbose {
+		if len(schemas) > 0 {
+			log.Printf("Listing tables of schemas %v (exact counts: %v)", schemas, exactCounts)
+		} else {
+			log.Printf("Listing tables of all schemas (exact counts: %v)", exactCounts)
+		}
+	}
+
+	cfg, db1, db2 := connectDatabases(envFile, "-table-overview", verbose)
+	defer db1.Close()
+	defer db2.Close()
+
+	comp := comparator.NewConcurrentComparator(db1, db2, maxWorkers)
+
+	result, err := comp.TableOverview(schemas, exactCounts)
+	if err != nil {
+		log.Fatalf("Failed to build table overview: %v", err)
+	}
+
+	// The overview writes to its own file unless -output was given
+	outputFileName := "table_overview.json"
+	if outputFile != "" {
+		outputFileName = outputFile
+	}
+	if err := outputResults(result, outputFileName, cfg.OutputFormat); err != nil {
+		log.Fatalf("Failed to output results: %v", err)
+	}
+
+	printTableOverview(result)
+}
+
+// printTableOverview prints the tables of both databases side by side, marking diverging tables with *
+func printTableOverview(result *models.TableOverviewResult) {
+	countKind := "estimated"
+	if result.ExactCounts {
+		countKind = "exact"
+	}
+
+	fmt.Printf("\n=== TABLE OVERVIEW (%s row counts) ===\n", countKind)
+	fmt.Printf("  %-40s %14s %14s %12s %10s %10s  %-16s %-16s\n",
+		"TABLE", "DB1 ROWS", "DB2 ROWS", "DELTA", "DB1 SIZE", "DB2 SIZE", "DB1 ANALYZED", "DB2 ANALYZED")
+
+	for _, table := range result.Tables {
+		marker := " "
+		if table.Diverges {
+			marker = "*"
+		}
+		rows1, size1, analyzed1 := tableStatsColumns(table.DB1)
+		rows2, size2, analyzed2 := tableStatsColumns(table.DB2)
+		fmt.Printf("%s %-40s %14s %14s %12d %10s %10s  %-16s %-16s\n", marker, table.Schema+"."+table.TableName,
+			rows1, rows2, table.RowDelta, size1, size2, analyzed1, analyzed2)
+	}
+
+	fmt.Printf("\nTables: %d, diverging (*): %d\n", result.TotalTables, result.DivergingTables)
+	if !result.ExactCounts {
+		fmt.Printf("Estimates come from the last analyze; use -exact-counts for exact figures\n")
+	}
 	fmt.Printf("\n=========================\n")
 }
 
+// tableStatsColumns formats the row count, size and last analyze time of a table, or dashes when it is missing
+func tableStatsColumns(stats *models.TableStats) (string, string, string) {
+	if stats == nil {
+		return "-", "-", "missing"
+	}
+
+	rows := fmt.Sprintf("%d", stats.RowCount)
+	if stats.RowCount < 0 {
+		rows = "unknown"
+	}
+
+	analyzed := "never"
+	if stats.LastAnalyzed != nil {
+		analyzed = stats.LastAnalyzed.Format("2006-01-02 15:04")
+	}
+
+	return rows, stats.TotalSize, analyzed
+}
+
 // handleFindReferences handles the find-references mode
 func handleFindReferences(envFile, schemaName, tableName, targetColumn, outputFile string, verbose bool, maxWorkers int, decodeUUIDs bool) {
 	if verbose {
@@ -0,0 +1,248 @@
+package comparator
+
+import (
+	"fmt"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+)
+
+// childFetchBatchSize is the number of parent keys looked up per query when fetching child rows
+const childFetchBatchSize = 1000
+
+// childTable is a table referencing the compared table, with the rows of the matched parents
+// grouped by parent key in each database
+type childTable struct {
+	fk       models.ForeignKey // ColumnName is the parent key, ReferencedTable the child table and ReferencedColumnName its referencing column
+	criteria *models.MatchCriteria
+	rules    *columnRules
+	rows1    map[string][]models.TableRow
+	rows2    map[string][]models.TableRow
+}
+
+// loadChildTables finds the tables referencing the primary key of the compared table and fetches
+// from both databases their rows belonging to the matched parents. A table referencing itself is
+// not an aggregate and is skipped.
+func (c *Comparator) loadChildTables(schema1 *models.TableSchema, matches []rowMatch, criteria *models.MatchCriteria) ([]*childTable, error) {
+	if len(schema1.PrimaryKey) != 1 {
+		return nil, fmt.Errorf("aggregate comparison requires a single-column primary key on %s.%s", schema1.Schema, schema1.TableName)
+	}
+	parentKey := schema1.PrimaryKey[0]
+
+	referencing, err := c.DB1.GetReferencingTables(schema1.Schema, schema1.TableName, parentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find child tables of %s.%s: %w", schema1.Schema, schema1.TableName, err)
+	}
+
+	var keys1, keys2 []interface{}
+	for _, match := range matches {
+		if val := match.row1[parentKey]; val != nil {
+			keys1 = append(keys1, val)
+		}
+		if val := match.row2[parentKey]; val != nil {
+			keys2 = append(keys2, val)
+		}
+	}
+
+	var children []*childTable
+	selected := make(map[string]bool)
+	for _, ref := range referencing {
+		if ref.ReferencedSchema == schema1.Schema && ref.ReferencedTable == schema1.TableName {
+			continue
+		}
+		if len(criteria.ChildTables) > 0 && !tableMatchesPattern(ref.ReferencedSchema, ref.ReferencedTable, criteria.ChildTables) {
+			continue
+		}
+
+		childFK := models.ForeignKey{
+			ColumnName:           parentKey,
+			ReferencedSchema:     ref.ReferencedSchema,
+			ReferencedTable:      ref.ReferencedTable,
+			ReferencedColumnName: ref.ColumnName,
+			ConstraintName:       ref.ConstraintName,
+		}
+
+		childSchema, err := c.DB1.GetTableSchema(ref.ReferencedSchema, ref.ReferencedTable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get schema of child table %s.%s: %w", ref.ReferencedSchema, ref.ReferencedTable, err)
+		}
+
+		childFK2, target2 := referencedTableInDB2(childFK, schema1.Schema, schema1.TableName, criteria)
+		rows1, err := fetchChildRows(c.DB1, childFK, keys1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get DB1 rows of child table %s.%s: %w", ref.ReferencedSchema, ref.ReferencedTable, err)
+		}
+		rows2, err := fetchChildRows(c.DB2, childFK2, keys2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get DB2 rows of child table %s.%s: %w", target2.Schema, target2.Table, err)
+		}
+
+		childCriteria := c.childMatchCriteria(childSchema, ref.ColumnName, criteria)
+		children = append(children, &childTable{
+			fk:       childFK,
+			criteria: childCriteria,
+			rules:    newColumnRules(childSchema, childCriteria),
+			rows1:    groupChildRows(rows1, ref.ColumnName),
+			rows2:    groupChildRows(target2.CanonicalRows(rows2), ref.ColumnName),
+		})
+		selected[ref.ReferencedSchema+"."+ref.ReferencedTable] = true
+	}
+
+	for _, pattern := range criteria.ChildTables {
+		found := false
+		for table := range selected {
+			schema, tableName := splitTablePattern(table, "")
+			if tableMatchesPattern(schema, tableName, []string{pattern}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("child table %s does not reference %s.%s", pattern, schema1.Schema, schema1.TableName)
+		}
+	}
+
+	return children, nil
+}
+
+// fetchChildRows fetches the rows of a child table referencing the given parent keys, in batches
+func fetchChildRows(conn *database.Connection, fk models.ForeignKey, keys []interface{}) ([]models.TableRow, error) {
+	var rows []models.TableRow
+	for start := 0; start < len(keys); start += childFetchBatchSize {
+		end := start + childFetchBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		batch, err := conn.GetForeignKeyData(fk, keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, batch...)
+	}
+	return rows, nil
+}
+
+// groupChildRows groups child rows by the parent key they reference
+func groupChildRows(rows []models.TableRow, column string) map[string][]models.TableRow {
+	groups := make(map[string][]models.TableRow)
+	for _, row := range rows {
+		key := childGroupKey(row[column])
+		groups[key] = append(groups[key], row)
+	}
+	return groups
+}
+
+// childGroupKey returns the grouping key of a parent key value
+func childGroupKey(value interface{}) string {
+	return fmt.Sprintf("%v", convertBytesToString(value))
+}
+
+// childMatchCriteria creates the criteria pairing the child rows of one parent: the default criteria
+// of the child table without the referencing column, which differs whenever the parent keys do, and
+// the column rules of the compared table. Tolerances and NULL equivalents are kept only for the
+// child columns they suit, the others are skipped as optional columns are.
+func (c *Comparator) childMatchCriteria(childSchema *models.TableSchema, parentColumn string, criteria *models.MatchCriteria) *models.MatchCriteria {
+	childCriteria := c.createDefaultMatchCriteria(childSchema)
+	childCriteria.ExcludeColumns = append(childCriteria.ExcludeColumns, parentColumn)
+	childCriteria.ExcludeColumnsFromFile = criteria.ExcludeColumnsFromFile
+	childCriteria.ExcludeColumnsFile = criteria.ExcludeColumnsFile
+	childCriteria.CaseInsensitiveColumns = criteria.CaseInsensitiveColumns
+	childCriteria.TrimColumns = criteria.TrimColumns
+	childCriteria.ReportWithinTolerance = criteria.ReportWithinTolerance
+	childCriteria.JSONIgnoreKeyOrder = criteria.JSONIgnoreKeyOrder
+	childCriteria.JSONIgnorePaths = criteria.JSONIgnorePaths
+	childCriteria.JSONUnorderedArrays = criteria.JSONUnorderedArrays
+	childCriteria.UnorderedArrayColumns = criteria.UnorderedArrayColumns
+
+	columns := make(map[string]string)
+	for _, col := range childSchema.Columns {
+		columns[col.ColumnName] = col.DataType
+	}
+	for col, value := range criteria.Tolerances {
+		tolerance, err := parseTolerance(value)
+		if dataType, exists := columns[col]; exists && err == nil && tolerance.temporal() == isTemporalType(dataType) {
+			if childCriteria.Tolerances == nil {
+				childCriteria.Tolerances = make(map[string]string)
+			}
+			childCriteria.Tolerances[col] = value
+		}
+	}
+	for col, kinds := range criteria.NullEquivalents {
+		for _, kind := range kinds {
+			if validateNullEquivalent(col, kind, columns) == nil {
+				if childCriteria.NullEquivalents == nil {
+					childCriteria.NullEquivalents = make(map[string][]string)
+				}
+				childCriteria.NullEquivalents[col] = append(childCriteria.NullEquivalents[col], kind)
+			}
+		}
+	}
+
+	var keyColumns []string
+	for _, col := range childCriteria.KeyColumns {
+		if col != parentColumn {
+			keyColumns = append(keyColumns, col)
+		}
+	}
+	childCriteria.KeyColumns = keyColumns
+
+	return childCriteria
+}
+
+// compareChildren compares the child rows of two matched parent rows as sets, table by table,
+// and returns the tables whose children differ
+func (c *Comparator) compareChildren(children []*childTable, row1, row2 models.TableRow) []models.ChildDifference {
+	var differences []models.ChildDifference
+
+	for _, child := range children {
+		childDiff := c.compareChildTable(child, row1, row2)
+		if len(childDiff.OnlyInDB1) > 0 || len(childDiff.OnlyInDB2) > 0 || len(childDiff.Differences) > 0 {
+			differences = append(differences, childDiff)
+		}
+	}
+
+	return differences
+}
+
+// compareChildTable compares the rows of one child table referencing either parent row. A parent
+// row missing from one database (nil) has no child rows there.
+func (c *Comparator) compareChildTable(child *childTable, row1, row2 models.TableRow) models.ChildDifference {
+	var rows1, rows2 []models.TableRow
+	if row1 != nil {
+		rows1 = child.rows1[childGroupKey(row1[child.fk.ColumnName])]
+	}
+	if row2 != nil {
+		rows2 = child.rows2[childGroupKey(row2[child.fk.ColumnName])]
+	}
+
+	matches, onlyInDB1, onlyInDB2, _ := c.matchRows(rows1, rows2, child.criteria, child.rules)
+	childDiff := models.ChildDifference{
+		Schema:       child.fk.ReferencedSchema,
+		TableName:    child.fk.ReferencedTable,
+		ColumnName:   child.fk.ReferencedColumnName,
+		TotalRowsDB1: len(rows1),
+		TotalRowsDB2: len(rows2),
+		OnlyInDB1:    onlyInDB1,
+		OnlyInDB2:    onlyInDB2,
+	}
+
+	for _, match := range matches {
+		diff := c.compareRows(match.row1, match.row2, child.criteria, child.rules)
+		if len(diff.ColumnDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, child.criteria, child.rules)
+			childDiff.Differences = append(childDiff.Differences, *diff)
+		}
+	}
+
+	return childDiff
+}
+
+// childTableNames returns the compared child tables as schema.table.column
+func childTableNames(children []*childTable) []string {
+	names := make([]string, len(children))
+	for i, child := range children {
+		names[i] = child.fk.ReferencedSchema + "." + child.fk.ReferencedTable + "." + child.fk.ReferencedColumnName
+	}
+	return names
+}
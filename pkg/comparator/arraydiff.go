@@ -0,0 +1,227 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"deepComparator/pkg/models"
+)
+
+// isArrayType reports whether a PostgreSQL data type is an array
+func isArrayType(dataType string) bool {
+	return dataType == "ARRAY"
+}
+
+// parseArrayLiteral decodes the text form of a PostgreSQL array, such as {a,"b c",NULL} or
+// {{1,2},{3,4}}, into elements that are strings, nil for NULL, or nested slices
+func parseArrayLiteral(literal string) ([]interface{}, error) {
+	literal = strings.TrimSpace(literal)
+
+	// Arrays with non-default bounds are prefixed with their dimensions, e.g. [0:1]={a,b}
+	if strings.HasPrefix(literal, "[") {
+		idx := strings.Index(literal, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid array literal %q", literal)
+		}
+		literal = literal[idx+1:]
+	}
+
+	elements, rest, err := parseArrayLevel(literal)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected data after array literal %q", literal)
+	}
+
+	return elements, nil
+}
+
+// parseArrayLevel parses one brace-delimited level of an array literal and returns the unparsed remainder
+func parseArrayLevel(s string) ([]interface{}, string, error) {
+	if !strings.HasPrefix(s, "{") {
+		return nil, s, fmt.Errorf("array literal must start with {")
+	}
+	s = s[1:]
+
+	elements := []interface{}{}
+	if strings.HasPrefix(s, "}") {
+		return elements, s[1:], nil
+	}
+
+	for {
+		switch {
+		case strings.HasPrefix(s, "{"):
+			nested, rest, err := parseArrayLevel(s)
+			if err != nil {
+				return nil, s, err
+			}
+			elements = append(elements, nested)
+			s = rest
+
+		case strings.HasPrefix(s, `"`):
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, s, fmt.Errorf("unterminated quoted array element")
+			}
+			elements = append(elements, b.String())
+			s = s[i+1:]
+
+		default:
+			end := strings.IndexAny(s, ",}")
+			if end < 0 {
+				return nil, s, fmt.Errorf("unterminated array literal")
+			}
+			element := strings.TrimSpace(s[:end])
+			if strings.EqualFold(element, "NULL") {
+				elements = append(elements, nil)
+			} else {
+				elements = append(elements, element)
+			}
+			s = s[end:]
+		}
+
+		if s == "" {
+			return nil, s, fmt.Errorf("unterminated array literal")
+		}
+		if s[0] == '}' {
+			return elements, s[1:], nil
+		}
+		if s[0] != ',' {
+			return nil, s, fmt.Errorf("unexpected %q in array literal", s[0])
+		}
+		s = s[1:]
+	}
+}
+
+// normalizeArrayElement returns the canonical form of an array element given the array's udt name (e.g. _numeric)
+func (r *columnRules) normalizeArrayElement(col string, element interface{}) interface{} {
+	switch v := element.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, nested := range v {
+			normalized[i] = r.normalizeArrayElement(col, nested)
+		}
+		return normalized
+	case string:
+		switch r.udtNames[col] {
+		case "_numeric", "_int2", "_int4", "_int8":
+			v = canonicalNumeric(v)
+		case "_float4", "_float8":
+			digits := doublePrecisionDigits
+			if r.udtNames[col] == "_float4" {
+				digits = realDigits
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				v = strconv.FormatFloat(roundSignificant(f, digits), 'g', -1, 64)
+			}
+		case "_bpchar":
+			v = strings.TrimRight(v, " ")
+		case "_uuid":
+			v = strings.ToLower(v)
+		}
+		if hasOption(r.trim, col) {
+			v = strings.TrimSpace(v)
+		}
+		if hasOption(r.caseInsensitive, col) {
+			v = strings.ToLower(v)
+		}
+		return v
+	}
+	return element
+}
+
+// decodeArray parses and normalizes the value of an array column
+func (r *columnRules) decodeArray(col string, value interface{}) ([]interface{}, bool) {
+	literal, ok := convertBytesToString(value).(string)
+	if !ok {
+		return nil, false
+	}
+
+	elements, err := parseArrayLiteral(literal)
+	if err != nil {
+		return nil, false
+	}
+
+	for i, element := range elements {
+		elements[i] = r.normalizeArrayElement(col, element)
+	}
+	return elements, true
+}
+
+// canonicalArray encodes normalized array elements, sorted when the column is compared as unordered
+func (r *columnRules) canonicalArray(col string, elements []interface{}) string {
+	encoded := encodeArrayElements(elements)
+	if hasOption(r.unorderedArrays, col) {
+		sort.Strings(encoded)
+	}
+	return "{" + strings.Join(encoded, ",") + "}"
+}
+
+// arrayDiff returns the elements added and removed between two values of an array column,
+// or nil when the column is not an array or a value cannot be decoded
+func (r *columnRules) arrayDiff(col string, val1, val2 interface{}) *models.ArrayDifference {
+	if !isArrayType(r.types[col]) || val1 == nil || val2 == nil {
+		return nil
+	}
+
+	elements1, ok1 := r.decodeArray(col, val1)
+	elements2, ok2 := r.decodeArray(col, val2)
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	encoded1 := encodeArrayElements(elements1)
+	encoded2 := encodeArrayElements(elements2)
+
+	remaining := make(map[string]int)
+	for _, encoded := range encoded2 {
+		remaining[encoded]++
+	}
+
+	diff := &models.ArrayDifference{
+		Added:   []interface{}{},
+		Removed: []interface{}{},
+	}
+	for i, encoded := range encoded1 {
+		if remaining[encoded] > 0 {
+			remaining[encoded]--
+			continue
+		}
+		diff.Removed = append(diff.Removed, elements1[i])
+	}
+	for i, encoded := range encoded2 {
+		if remaining[encoded] > 0 {
+			remaining[encoded]--
+			diff.Added = append(diff.Added, elements2[i])
+		}
+	}
+
+	// Same elements in a different order only matter for ordered columns
+	diff.OrderChanged = len(diff.Added) == 0 && len(diff.Removed) == 0 &&
+		!hasOption(r.unorderedArrays, col) && strings.Join(encoded1, ",") != strings.Join(encoded2, ",")
+
+	return diff
+}
+
+// encodeArrayElements returns the JSON encoding of each element, used to compare elements
+func encodeArrayElements(elements []interface{}) []string {
+	encoded := make([]string, len(elements))
+	for i, element := range elements {
+		b, _ := json.Marshal(element)
+		encoded[i] = string(b)
+	}
+	return encoded
+}
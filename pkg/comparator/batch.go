@@ -0,0 +1,201 @@
+package comparator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+)
+
+// TableComparison compares the data of one table; CompareTable and the chunked and sampled
+// comparisons fit it
+type TableComparison func(schema, tableName string, criteria *models.MatchCriteria) (*models.ComparisonResult, error)
+
+// ResolveTables expands table patterns into the tables of DB1 to compare. A pattern is a table name
+// or a glob (orders, order_*), optionally qualified by a schema (billing.invoices, billing.*);
+// unqualified patterns use defaultSchema. Exclude patterns use the same syntax, an unqualified
+// one matching tables of any schema. Tables are returned in pattern order, without duplicates.
+func (c *Comparator) ResolveTables(patterns, excludes []string, defaultSchema string) ([]models.TableTarget, error) {
+	for _, pattern := range append(append([]string{}, patterns...), excludes...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+
+	listed := make(map[string][]string)
+	seen := make(map[string]bool)
+	var tables []models.TableTarget
+
+	for _, pattern := range patterns {
+		schema, tablePattern := splitTablePattern(pattern, defaultSchema)
+
+		// Plain names are compared as given, so that a missing table is reported rather than skipped
+		candidates := []string{tablePattern}
+		if strings.ContainsAny(tablePattern, "*?[") {
+			names, cached := listed[schema]
+			if !cached {
+				var err error
+				names, err = c.DB1.ListTables(schema)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list tables of schema %s: %w", schema, err)
+				}
+				listed[schema] = names
+			}
+
+			candidates = nil
+			for _, name := range names {
+				if matched, _ := path.Match(tablePattern, name); matched {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+
+		for _, name := range candidates {
+			qualified := schema + "." + name
+			if seen[qualified] || tableMatchesPattern(schema, name, excludes) {
+				continue
+			}
+			seen[qualified] = true
+			tables = append(tables, models.TableTarget{Schema: schema, Table: name})
+		}
+	}
+
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no table matches %s", strings.Join(patterns, ", "))
+	}
+
+	return tables, nil
+}
+
+// splitTablePattern splits a table pattern into its schema, defaulting to defaultSchema, and table part
+func splitTablePattern(pattern, defaultSchema string) (string, string) {
+	if idx := strings.Index(pattern, "."); idx > 0 {
+		return pattern[:idx], pattern[idx+1:]
+	}
+	return defaultSchema, pattern
+}
+
+// tableMatchesPattern reports whether a table matches one of the table patterns
+func tableMatchesPattern(schema, tableName string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, ".") {
+			patternSchema, patternTable := splitTablePattern(pattern, "")
+			schemaMatched, _ := path.Match(patternSchema, schema)
+			tableMatched, _ := path.Match(patternTable, tableName)
+			if schemaMatched && tableMatched {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern, tableName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareTables compares several tables, up to MaxWorkers at a time over the shared connections,
+// and aggregates their results. The criteria are shared by every table and are only read. Tables
+// whose comparison fails are reported as failed and do not stop the batch. Per-table progress
+// bars are replaced by one line per finished table.
+func (c *Comparator) CompareTables(tables []models.TableTarget, criteria *models.MatchCriteria, compare TableComparison) *models.BatchComparisonResult {
+	start := time.Now()
+	summaries := make([]models.BatchTableSummary, len(tables))
+	results := make([]*models.ComparisonResult, len(tables))
+
+	progress.SetQuiet(true)
+	defer progress.SetQuiet(false)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	finished := 0
+
+	workers := c.MaxWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	semaphore := make(chan struct{}, workers)
+
+	for i, table := range tables {
+		wg.Add(1)
+		go func(idx int, table models.TableTarget) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
+			defer func() { <-semaphore }() // Release semaphore
+
+			tableStart := time.Now()
+			result, err := compare(table.Schema, table.Table, criteria)
+			summary := batchSummary(table, result, err)
+			summary.DurationMs = time.Since(tableStart).Milliseconds()
+
+			mu.Lock()
+			summaries[idx] = summary
+			results[idx] = result
+			finished++
+			fmt.Printf("[%d/%d] %s.%s: %s\n", finished, len(tables), table.Schema, table.Table, describeBatchSummary(summary))
+			mu.Unlock()
+		}(i, table)
+	}
+
+	wg.Wait()
+
+	batch := &models.BatchComparisonResult{
+		Timestamp:   start,
+		TotalTables: len(tables),
+		DurationMs:  time.Since(start).Milliseconds(),
+		Summaries:   summaries,
+		Results:     []*models.ComparisonResult{},
+	}
+	for i, summary := range summaries {
+		switch summary.Status {
+		case models.BatchStatusIdentical:
+			batch.IdenticalTables++
+		case models.BatchStatusDifferent:
+			batch.DifferentTables++
+		case models.BatchStatusFailed:
+			batch.FailedTables++
+		}
+		if results[i] != nil {
+			batch.Results = append(batch.Results, results[i])
+		}
+	}
+
+	return batch
+}
+
+// batchSummary summarizes the outcome of the comparison of one table
+func batchSummary(table models.TableTarget, result *models.ComparisonResult, err error) models.BatchTableSummary {
+	summary := models.BatchTableSummary{Schema: table.Schema, TableName: table.Table}
+	if err != nil {
+		summary.Status = models.BatchStatusFailed
+		summary.Error = err.Error()
+		return summary
+	}
+
+	summary.TotalRowsDB1 = result.TotalRowsDB1
+	summary.TotalRowsDB2 = result.TotalRowsDB2
+	summary.MatchedRows = result.MatchedRows
+	summary.OnlyInDB1 = len(result.OnlyInDB1)
+	summary.OnlyInDB2 = len(result.OnlyInDB2)
+	summary.Differences = len(result.Differences)
+
+	summary.Status = models.BatchStatusIdentical
+	if summary.OnlyInDB1 > 0 || summary.OnlyInDB2 > 0 || summary.Differences > 0 || len(result.DuplicateKeys) > 0 {
+		summary.Status = models.BatchStatusDifferent
+	}
+	return summary
+}
+
+// describeBatchSummary formats the outcome of one table for the progress line
+func describeBatchSummary(summary models.BatchTableSummary) string {
+	switch summary.Status {
+	case models.BatchStatusFailed:
+		return "failed: " + summary.Error
+	case models.BatchStatusDifferent:
+		return fmt.Sprintf("%d differences, %d only in DB1, %d only in DB2 (%dms)",
+			summary.Differences, summary.OnlyInDB1, summary.OnlyInDB2, summary.DurationMs)
+	}
+	return fmt.Sprintf("identical, %d rows (%dms)", summary.TotalRowsDB1, summary.DurationMs)
+}
@@ -0,0 +1,263 @@
+package comparator
+
+import (
+	"fmt"
+	"time"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+)
+
+// Default settings of the chunked checksum comparison
+const (
+	DefaultChecksumChunks   = 16
+	DefaultChecksumLeafSize = 1000
+)
+
+// CompareTableChunked compares a table by hashing key ranges inside PostgreSQL on both databases.
+// The table is first split into chunks; ranges whose checksums differ are bisected recursively
+// and only the rows of leaf ranges of at most leafSize rows that still disagree are fetched and
+// compared. Ranges are defined on the business key, or on the primary key when none is given,
+// and the rows of mismatching leaves are paired on that same key.
+func (c *Comparator) CompareTableChunked(schema, tableName string, criteria *models.MatchCriteria, chunks, leafSize int) (*models.ComparisonResult, error) {
+	if chunks < 1 {
+		chunks = DefaultChecksumChunks
+	}
+	if leafSize < 1 {
+		leafSize = DefaultChecksumLeafSize
+	}
+
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, _, err := c.loadTableSchemas(target1, target2)
+	if err != nil {
+		return nil, err
+	}
+
+	if criteria == nil {
+		criteria = c.createDefaultMatchCriteria(schema1)
+	}
+
+	if err := validateCriteria(schema1, criteria); err != nil {
+		return nil, err
+	}
+
+	rangeCriteria, err := keyedCriteria(schema1, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("checksum comparison: %w", err)
+	}
+
+	columns := make([]string, len(schema1.Columns))
+	for i, col := range schema1.Columns {
+		columns[i] = col.ColumnName
+	}
+
+	keyColumns := rangeCriteria.KeyColumns
+	rules := newColumnRules(schema1, criteria)
+	hashColumns := append(append([]string{}, keyColumns...), c.comparedColumns(columns, rangeCriteria, rules)...)
+
+	result := &models.ComparisonResult{
+		TableName:         tableName,
+		Schema:            schema,
+		Timestamp:         time.Now(),
+		OnlyInDB1:         []models.TableRow{},
+		OnlyInDB2:         []models.TableRow{},
+		Differences:       []models.RowDifference{},
+		ForeignKeyResults: []models.ForeignKeyResult{},
+		WhereDB1:          criteria.WhereDB1,
+		WhereDB2:          criteria.WhereDB2,
+		Checksum:          &models.ChecksumSummary{KeyColumns: keyColumns},
+	}
+	recordDB2Target(result, target1, target2)
+	summary := result.Checksum
+
+	// Hash the whole key space (plus rows with NULL keys) first, then split into chunks
+	// and keep bisecting only the ranges that disagree
+	pending := []models.KeyRange{{}, {NullKeys: true}}
+	parts := chunks
+	var leaves []models.KeyRange
+
+	checksumProgress := progress.NewSimpleProgress("Comparing range checksums")
+	for level := 0; len(pending) > 0; level++ {
+		pairs, err := c.ConcurrentWorker.ParallelRangeChecksums(target1, target2, keyColumns, hashColumns, pending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute range checksums: %w", err)
+		}
+
+		var next []models.KeyRange
+		for _, pair := range pairs {
+			summary.RangesCompared++
+			checksumProgress.Update(1)
+
+			if level == 0 {
+				result.TotalRowsDB1 += int(pair.DB1.RowCount)
+				result.TotalRowsDB2 += int(pair.DB2.RowCount)
+			}
+
+			if pair.Matches() {
+				summary.MatchingRanges++
+				result.MatchedRows += int(pair.DB1.RowCount)
+				continue
+			}
+
+			subRanges, err := c.splitKeyRange(target1, target2, keyColumns, pair, parts, leafSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(subRanges) == 0 {
+				leaves = append(leaves, pair.Range)
+			} else {
+				next = append(next, subRanges...)
+			}
+		}
+
+		pending = next
+		parts = 2
+	}
+	checksumProgress.Finish(fmt.Sprintf("%d of %d ranges match, %d leaves to fetch",
+		summary.MatchingRanges, summary.RangesCompared, len(leaves)))
+
+	// Fetch and compare the rows of the leaves that still disagree
+	var leafProgress *progress.ProgressBar
+	if len(leaves) > 0 {
+		leafProgress = progress.NewProgressBar(int64(len(leaves)), "Comparing mismatching ranges")
+	}
+
+	var leafRows1, leafRows2 []models.TableRow
+	for _, leaf := range leaves {
+		rows1, rows2, err := c.ConcurrentWorker.ParallelRangeFetch(target1, target2, keyColumns, leaf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch mismatching range: %w", err)
+		}
+
+		summary.MismatchedLeaves++
+		summary.RowsFetchedDB1 += len(rows1)
+		summary.RowsFetchedDB2 += len(rows2)
+		leafRows1 = append(leafRows1, rows1...)
+		leafRows2 = append(leafRows2, rows2...)
+
+		leafProgress.Update(1)
+	}
+
+	// Rows whose keys only match once normalized can sort into different ranges, so the rows of
+	// all mismatching leaves are paired together
+	matches, onlyInDB1, onlyInDB2, duplicates := c.matchRows(leafRows1, leafRows2, rangeCriteria, rules)
+	result.OnlyInDB1 = append(result.OnlyInDB1, onlyInDB1...)
+	result.OnlyInDB2 = append(result.OnlyInDB2, onlyInDB2...)
+	result.DuplicateKeys = append(result.DuplicateKeys, duplicates...)
+	result.MatchedRows += len(matches)
+
+	for _, match := range matches {
+		diff := c.compareRowsWithFK(match.row1, match.row2, rangeCriteria, rules, schema1)
+		if len(diff.ColumnDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, rangeCriteria, rules)
+			result.Differences = append(result.Differences, *diff)
+		} else if len(diff.ToleratedDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, rangeCriteria, rules)
+			result.WithinTolerance = append(result.WithinTolerance, *diff)
+		}
+		if len(diff.ColumnDifferences) == 0 && len(diff.NullEquivalentColumns) > 0 {
+			result.NullEquivalentRows++
+		}
+	}
+
+	if leafProgress != nil {
+		leafProgress.FinishWithMessage(fmt.Sprintf("Found %d differences in %d fetched rows",
+			len(result.Differences), summary.RowsFetchedDB1+summary.RowsFetchedDB2))
+	}
+
+	result.UnmatchedRows = len(result.OnlyInDB1) + len(result.OnlyInDB2)
+	result.ProbableMatches = c.findProbableMatches(result.OnlyInDB1, result.OnlyInDB2, criteria, rules)
+
+	// Compare foreign key relationships of the fetched rows only
+	if len(leaves) > 0 {
+		data1 := &models.TableData{TableName: tableName, Schema: schema, Rows: leafRows1}
+		data2 := &models.TableData{TableName: tableName, Schema: schema, Rows: leafRows2}
+		traversal := newFKTraversal(schema, tableName, criteria)
+		for _, fk := range schema1.ForeignKeys {
+			fkResult := c.compareForeignKey(fk, data1, data2, traversal, 1)
+			result.ForeignKeyResults = append(result.ForeignKeyResults, *fkResult)
+		}
+	}
+
+	// Process UUIDs if enabled
+	result = c.UUIDDecoder.ProcessComparisonResult(result)
+
+	return result, nil
+}
+
+// splitKeyRange splits a mismatching range into sub-ranges using split points taken from the
+// database holding more rows. It returns nil when the range is small enough to be fetched,
+// holds NULL keys, or cannot be split further because all its rows share the same key.
+func (c *Comparator) splitKeyRange(target1, target2 models.TableTarget, keyColumns []string, pair models.RangeChecksumPair, parts, leafSize int) ([]models.KeyRange, error) {
+	if pair.Range.NullKeys {
+		return nil, nil
+	}
+
+	var conn *database.Connection
+	var rowCount int64
+	var target models.TableTarget
+	if pair.DB1.RowCount >= pair.DB2.RowCount {
+		conn, rowCount, target = c.DB1, pair.DB1.RowCount, target1
+	} else {
+		conn, rowCount, target = c.DB2, pair.DB2.RowCount, target2
+	}
+
+	if rowCount <= int64(leafSize) {
+		return nil, nil
+	}
+
+	points, err := conn.GetRangeSplitPoints(target.Schema, target.Table, target.Where, target.ColumnList(keyColumns), pair.Range, rowCount, parts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split key range: %w", err)
+	}
+
+	var ranges []models.KeyRange
+	lower := pair.Range.Lower
+	for _, point := range points {
+		// Duplicate keys can produce repeated split points
+		if lower != nil && equalKeyValues(lower, point) {
+			continue
+		}
+		ranges = append(ranges, models.KeyRange{Lower: lower, Upper: point})
+		lower = point
+	}
+	ranges = append(ranges, models.KeyRange{Lower: lower, Upper: pair.Range.Upper})
+
+	if len(ranges) < 2 {
+		return nil, nil
+	}
+
+	return ranges, nil
+}
+
+// keyedCriteria returns criteria whose KeyColumns are set, falling back to the primary key or,
+// without one, a NOT NULL unique key when no business key was given. Modes that address rows
+// by key in SQL need it.
+func keyedCriteria(schema *models.TableSchema, criteria *models.MatchCriteria) (*models.MatchCriteria, error) {
+	if len(criteria.KeyColumns) > 0 {
+		return criteria, nil
+	}
+
+	rowKey := schema.RowKey()
+	if len(rowKey) == 0 {
+		return nil, fmt.Errorf("%s.%s requires a primary key, a NOT NULL unique key or -key columns", schema.Schema, schema.TableName)
+	}
+
+	withKey := *criteria
+	withKey.KeyColumns = rowKey
+	return &withKey, nil
+}
+
+// equalKeyValues reports whether two key values are identical
+func equalKeyValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -66,36 +66,24 @@ func NewComparatorWithUUIDDecoding(db1, db2 *database.Connection, maxWorkers int
 
 // CompareTable compares a table between two databases
 func (c *Comparator) CompareTable(schema, tableName string, criteria *models.MatchCriteria) (*models.ComparisonResult, error) {
-	// Check if table exists in both databases
-	exists1, err := c.DB1.TableExists(schema, tableName)
+	// Check if table exists in both databases and get their schemas
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, _, err := c.loadTableSchemas(target1, target2)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check table existence in DB1: %w", err)
-	}
-	if !exists1 {
-		return nil, fmt.Errorf("table %s.%s does not exist in database 1", schema, tableName)
-	}
-
-	exists2, err := c.DB2.TableExists(schema, tableName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check table existence in DB2: %w", err)
-	}
-	if !exists2 {
-		return nil, fmt.Errorf("table %s.%s does not exist in database 2", schema, tableName)
+		return nil, err
 	}
 
-	// Get table schemas
-	schema1, err := c.DB1.GetTableSchema(schema, tableName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get schema from DB1: %w", err)
+	// Create match criteria if not provided
+	if criteria == nil {
+		criteria = c.createDefaultMatchCriteria(schema1)
 	}
 
-	_, err = c.DB2.GetTableSchema(schema, tableName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get schema from DB2: %w", err)
+	if err := validateCriteria(schema1, criteria); err != nil {
+		return nil, err
 	}
 
 	// Get table data using concurrent operations
-	data1, data2, _, err := c.ConcurrentWorker.ParallelDataFetch(schema, tableName)
+	data1, data2, _, err := c.ConcurrentWorker.ParallelDataFetch(target1, target2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data using parallel fetch: %w", err)
 	}
@@ -111,23 +99,35 @@ func (c *Comparator) CompareTable(schema, tableName string, criteria *models.Mat
 		OnlyInDB2:         []models.TableRow{},
 		Differences:       []models.RowDifference{},
 		ForeignKeyResults: []models.ForeignKeyResult{},
+		WhereDB1:          criteria.WhereDB1,
+		WhereDB2:          criteria.WhereDB2,
 	}
-
-	// Create match criteria if not provided
-	if criteria == nil {
-		criteria = c.createDefaultMatchCriteria(schema1)
-	}
+	recordDB2Target(result, target1, target2)
 
 	// Match rows between databases
 	matchProgress := progress.NewSimpleProgress("Matching rows")
-	matches, onlyInDB1, onlyInDB2 := c.matchRows(data1.Rows, data2.Rows, criteria)
+	rules := newColumnRules(schema1, criteria)
+	matches, onlyInDB1, onlyInDB2, duplicates := c.matchRows(data1.Rows, data2.Rows, criteria, rules)
 	matchProgress.Finish(fmt.Sprintf("Found %d matches", len(matches)))
 
 	result.OnlyInDB1 = onlyInDB1
 	result.OnlyInDB2 = onlyInDB2
+	result.DuplicateKeys = duplicates
 	result.MatchedRows = len(matches)
 	result.UnmatchedRows = len(onlyInDB1) + len(onlyInDB2)
 
+	// An aggregate comparison also compares the child rows of every matched row
+	var children []*childTable
+	if criteria.Aggregate {
+		childProgress := progress.NewSimpleProgress("Loading child rows")
+		children, err = c.loadChildTables(schema1, matches, criteria)
+		if err != nil {
+			return nil, err
+		}
+		result.ChildTables = childTableNames(children)
+		childProgress.Finish(fmt.Sprintf("Found %d child tables", len(children)))
+	}
+
 	// Compare matched rows for differences
 	var comparisonProgress *progress.ProgressBar
 	if len(matches) > 0 { // Show progress bar for any number of matches
@@ -135,10 +135,17 @@ func (c *Comparator) CompareTable(schema, tableName string, criteria *models.Mat
 	}
 
 	for i, match := range matches {
-		diff := c.compareRowsWithFK(match.row1, match.row2, criteria, schema1.ForeignKeys)
-		if len(diff.ColumnDifferences) > 0 {
-			diff.RowIdentifier = c.getRowIdentifier(match.row1, criteria)
+		diff := c.compareRowsWithFK(match.row1, match.row2, criteria, rules, schema1)
+		diff.ChildDifferences = c.compareChildren(children, match.row1, match.row2)
+		if len(diff.ColumnDifferences) > 0 || len(diff.ChildDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, criteria, rules)
 			result.Differences = append(result.Differences, *diff)
+		} else if len(diff.ToleratedDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, criteria, rules)
+			result.WithinTolerance = append(result.WithinTolerance, *diff)
+		}
+		if len(diff.ColumnDifferences) == 0 && len(diff.NullEquivalentColumns) > 0 {
+			result.NullEquivalentRows++
 		}
 
 		if comparisonProgress != nil {
@@ -153,9 +160,12 @@ func (c *Comparator) CompareTable(schema, tableName string, criteria *models.Mat
 		comparisonProgress.FinishWithMessage(fmt.Sprintf("Found %d differences", len(result.Differences)))
 	}
 
-	// Compare foreign key relationships
+	result.ProbableMatches = c.findProbableMatches(result.OnlyInDB1, result.OnlyInDB2, criteria, rules)
+
+	// Compare foreign key relationships, following them up to the configured depth
+	traversal := newFKTraversal(schema, tableName, criteria)
 	for _, fk := range schema1.ForeignKeys {
-		fkResult := c.compareForeignKey(fk, data1, data2, criteria)
+		fkResult := c.compareForeignKey(fk, data1, data2, traversal, 1)
 		result.ForeignKeyResults = append(result.ForeignKeyResults, *fkResult)
 	}
 
@@ -165,77 +175,281 @@ func (c *Comparator) CompareTable(schema, tableName string, criteria *models.Mat
 	return result, nil
 }
 
+// loadTableSchemas returns both schemas of the compared table, as fetchTableSchemas does, and
+// warns when their structures disagree. Data comparisons use it.
+func (c *Comparator) loadTableSchemas(target1, target2 models.TableTarget) (*models.TableSchema, *models.TableSchema, error) {
+	schema1, schema2, err := c.fetchTableSchemas(target1, target2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnSchemaDifferences(schema1, schema2, target2)
+
+	return schema1, schema2, nil
+}
+
+// fetchTableSchemas checks that the compared table exists in both databases and returns both schemas.
+// It also checks that every column of the DB2 column map exists on its side.
+func (c *Comparator) fetchTableSchemas(target1, target2 models.TableTarget) (*models.TableSchema, *models.TableSchema, error) {
+	exists1, err := c.DB1.TableExists(target1.Schema, target1.Table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check table existence in DB1: %w", err)
+	}
+	if !exists1 {
+		return nil, nil, fmt.Errorf("table %s.%s does not exist in database 1", target1.Schema, target1.Table)
+	}
+
+	exists2, err := c.DB2.TableExists(target2.Schema, target2.Table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check table existence in DB2: %w", err)
+	}
+	if !exists2 {
+		return nil, nil, fmt.Errorf("table %s.%s does not exist in database 2", target2.Schema, target2.Table)
+	}
+
+	schema1, err := c.DB1.GetTableSchema(target1.Schema, target1.Table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema from DB1: %w", err)
+	}
+
+	schema2, err := c.DB2.GetTableSchema(target2.Schema, target2.Table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema from DB2: %w", err)
+	}
+
+	if err := validateColumnMap(schema1, schema2, target2.ColumnMap); err != nil {
+		return nil, nil, err
+	}
+
+	return schema1, schema2, nil
+}
+
+// validateColumnMap checks that every mapped column exists in DB1 and its new name exists in DB2
+func validateColumnMap(schema1, schema2 *models.TableSchema, columnMap map[string]string) error {
+	columns1 := make(map[string]bool)
+	for _, col := range schema1.Columns {
+		columns1[col.ColumnName] = true
+	}
+	columns2 := make(map[string]bool)
+	for _, col := range schema2.Columns {
+		columns2[col.ColumnName] = true
+	}
+
+	mapped := make(map[string]string)
+	for col1, col2 := range columnMap {
+		if !columns1[col1] {
+			return fmt.Errorf("mapped column %s does not exist in %s.%s", col1, schema1.Schema, schema1.TableName)
+		}
+		if !columns2[col2] {
+			return fmt.Errorf("column %s mapped from %s does not exist in %s.%s", col2, col1, schema2.Schema, schema2.TableName)
+		}
+		if other, exists := mapped[col2]; exists {
+			return fmt.Errorf("columns %s and %s are both mapped to %s", other, col1, col2)
+		}
+		mapped[col2] = col1
+	}
+
+	return nil
+}
+
+// recordDB2Target records in a result the DB2 table and column names when they differ from DB1
+func recordDB2Target(result *models.ComparisonResult, target1, target2 models.TableTarget) {
+	if target2.Schema != target1.Schema {
+		result.DB2Schema = target2.Schema
+	}
+	if target2.Table != target1.Table {
+		result.DB2TableName = target2.Table
+	}
+	result.ColumnMap = target2.ColumnMap
+}
+
+// referencedTableInDB2 returns the foreign key to follow in DB2 and the DB2 table it references.
+// References into the compared schema follow DB2Schema, and references to the compared table
+// itself also follow DB2Table and the column map.
+func referencedTableInDB2(fk models.ForeignKey, schema, tableName string, criteria *models.MatchCriteria) (models.ForeignKey, models.TableTarget) {
+	target := models.TableTarget{Schema: fk.ReferencedSchema, Table: fk.ReferencedTable}
+	if fk.ReferencedSchema == schema {
+		_, compared2 := criteria.Targets(schema, tableName)
+		target.Schema = compared2.Schema
+		if fk.ReferencedTable == tableName {
+			target.Table = compared2.Table
+			target.ColumnMap = compared2.ColumnMap
+		}
+	}
+
+	fk2 := fk
+	fk2.ReferencedSchema = target.Schema
+	fk2.ReferencedTable = target.Table
+	fk2.ReferencedColumnName = target.Column(fk.ReferencedColumnName)
+	return fk2, target
+}
+
+// validateCriteria checks that every business key, tolerance and NULL equivalent column exists
+// in the table, unless the criteria make them optional, and that tolerances and NULL equivalents
+// suit the type of their column
+func validateCriteria(schema *models.TableSchema, criteria *models.MatchCriteria) error {
+	columns := make(map[string]string)
+	for _, col := range schema.Columns {
+		columns[col.ColumnName] = col.DataType
+	}
+
+	for _, col := range criteria.KeyColumns {
+		if _, exists := columns[col]; !exists {
+			return fmt.Errorf("key column %s does not exist in %s.%s", col, schema.Schema, schema.TableName)
+		}
+	}
+
+	for col, value := range criteria.Tolerances {
+		dataType, exists := columns[col]
+		if !exists && criteria.OptionalColumns {
+			continue
+		}
+		if !exists {
+			return fmt.Errorf("tolerance column %s does not exist in %s.%s", col, schema.Schema, schema.TableName)
+		}
+
+		tolerance, err := parseTolerance(value)
+		if err != nil {
+			return fmt.Errorf("invalid tolerance for column %s: %w", col, err)
+		}
+		if tolerance.temporal() != isTemporalType(dataType) {
+			return fmt.Errorf("tolerance %q does not apply to column %s of type %s", value, col, dataType)
+		}
+	}
+
+	for _, entry := range criteria.JSONIgnorePaths {
+		path := entry
+		if idx := strings.Index(entry, ":"); idx > 0 && !strings.HasPrefix(entry, "$") {
+			path = entry[idx+1:]
+		}
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("invalid JSON ignore path: %w", err)
+		}
+	}
+
+	for col, kinds := range criteria.NullEquivalents {
+		if _, exists := columns[col]; !exists && col != allColumnsOption && criteria.OptionalColumns {
+			continue
+		}
+		for _, kind := range kinds {
+			if err := validateNullEquivalent(col, kind, columns); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // rowMatch represents a matched pair of rows
 type rowMatch struct {
 	row1 models.TableRow
 	row2 models.TableRow
 }
 
-// matchRows matches rows between two datasets based on criteria
-func (c *Comparator) matchRows(rows1, rows2 []models.TableRow, criteria *models.MatchCriteria) ([]rowMatch, []models.TableRow, []models.TableRow) {
+// matchRows matches rows between two datasets based on criteria.
+// Rows are matched as a multiset: each key is paired as many times as it occurs on both sides,
+// surplus occurrences are reported as unmatched, and keys whose duplicate counts differ are returned separately.
+func (c *Comparator) matchRows(rows1, rows2 []models.TableRow, criteria *models.MatchCriteria, rules *columnRules) ([]rowMatch, []models.TableRow, []models.TableRow, []models.DuplicateKeyDifference) {
 	var matches []rowMatch
 	var onlyInDB1 []models.TableRow
 	var onlyInDB2 []models.TableRow
+	var duplicates []models.DuplicateKeyDifference
+
+	// Group rows by key, remembering the order in which keys first appear
+	groups1, keys1 := c.groupRowsByKey(rows1, criteria, rules)
+	groups2, keys2 := c.groupRowsByKey(rows2, criteria, rules)
+
+	// Pair keys present in DB1, collecting rows only in DB1 and surplus DB2 occurrences
+	for _, key := range keys1 {
+		keyMatches, keyOnly1, keyOnly2, dup := pairKeyGroup(key, groups1[key], groups2[key])
+		matches = append(matches, keyMatches...)
+		onlyInDB1 = append(onlyInDB1, keyOnly1...)
+		onlyInDB2 = append(onlyInDB2, keyOnly2...)
+		if dup != nil {
+			duplicates = append(duplicates, *dup)
+		}
+	}
 
-	// Create a map for faster lookup of rows2
-	rows2Map := make(map[string]models.TableRow)
-	for _, row2 := range rows2 {
-		key := c.getRowKey(row2, criteria)
-		rows2Map[key] = row2
+	// Find rows only in DB2
+	for _, key := range keys2 {
+		if _, exists := groups1[key]; exists {
+			continue
+		}
+		_, _, keyOnly2, dup := pairKeyGroup(key, nil, groups2[key])
+		onlyInDB2 = append(onlyInDB2, keyOnly2...)
+		if dup != nil {
+			duplicates = append(duplicates, *dup)
+		}
 	}
 
-	// Track which rows from DB2 have been matched
-	matchedInDB2 := make(map[string]bool)
+	return matches, onlyInDB1, onlyInDB2, duplicates
+}
+
+// groupRowsByKey groups rows by match key and returns the keys in order of first appearance
+func (c *Comparator) groupRowsByKey(rows []models.TableRow, criteria *models.MatchCriteria, rules *columnRules) (map[string][]models.TableRow, []string) {
+	groups := make(map[string][]models.TableRow)
+	var keys []string
 
-	// Find matches and rows only in DB1
-	for _, row1 := range rows1 {
-		key := c.getRowKey(row1, criteria)
-		if row2, exists := rows2Map[key]; exists {
-			matches = append(matches, rowMatch{row1: row1, row2: row2})
-			matchedInDB2[key] = true
-		} else {
-			onlyInDB1 = append(onlyInDB1, row1)
+	for _, row := range rows {
+		key := c.getRowKey(row, criteria, rules)
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
 		}
+		groups[key] = append(groups[key], row)
 	}
 
-	// Find rows only in DB2
-	for _, row2 := range rows2 {
-		key := c.getRowKey(row2, criteria)
-		if !matchedInDB2[key] {
-			onlyInDB2 = append(onlyInDB2, row2)
+	return groups, keys
+}
+
+// pairKeyGroup pairs the occurrences of one key on both sides in order. Occurrences without a
+// counterpart are returned as unmatched, and a duplicate difference is returned when the key
+// occurs more than once on either side with different counts.
+func pairKeyGroup(key string, rows1, rows2 []models.TableRow) ([]rowMatch, []models.TableRow, []models.TableRow, *models.DuplicateKeyDifference) {
+	paired := len(rows1)
+	if len(rows2) < paired {
+		paired = len(rows2)
+	}
+
+	matches := make([]rowMatch, 0, paired)
+	for i := 0; i < paired; i++ {
+		matches = append(matches, rowMatch{row1: rows1[i], row2: rows2[i]})
+	}
+
+	var dup *models.DuplicateKeyDifference
+	if len(rows1) != len(rows2) && (len(rows1) > 1 || len(rows2) > 1) {
+		dup = &models.DuplicateKeyDifference{
+			Key:      key,
+			CountDB1: len(rows1),
+			CountDB2: len(rows2),
+			DB1Rows:  append([]models.TableRow{}, rows1...),
+			DB2Rows:  append([]models.TableRow{}, rows2...),
 		}
 	}
 
-	return matches, onlyInDB1, onlyInDB2
+	return matches, rows1[paired:], rows2[paired:], dup
 }
 
-// getRowKey generates a key for matching rows based on criteria
-func (c *Comparator) getRowKey(row models.TableRow, criteria *models.MatchCriteria) string {
+// getRowKey generates a key for matching rows based on criteria, using normalized values
+func (c *Comparator) getRowKey(row models.TableRow, criteria *models.MatchCriteria, rules *columnRules) string {
 	var keyParts []string
 
-	// Build exclude map with explicit exclusions
-	excludeMap := make(map[string]bool)
-	for _, col := range criteria.ExcludeColumns {
-		excludeMap[col] = true
-	}
-
-	// Add columns from file to exclude map if enabled
-	fileColumns, err := c.getExcludeColumnsFromFile(criteria)
-	if err != nil {
-		// Log error but continue without file column exclusion
-		fmt.Printf("Warning: Could not load exclude columns from file: %v\n", err)
-	} else {
-		for _, col := range fileColumns {
-			excludeMap[col] = true
+	// An explicit business key pairs rows on those columns only
+	if len(criteria.KeyColumns) > 0 {
+		for _, col := range criteria.KeyColumns {
+			keyParts = append(keyParts, fmt.Sprintf("%s:%v", col, rules.keyValue(col, row[col])))
 		}
+		return strings.Join(keyParts, "|")
 	}
 
+	excludeMap := c.buildExcludeMap(criteria)
+
 	// If specific columns are defined, use only those
 	if len(criteria.Columns) > 0 {
 		for _, col := range criteria.Columns {
 			if !excludeMap[col] {
 				if val, exists := row[col]; exists {
-					keyParts = append(keyParts, fmt.Sprintf("%s:%v", col, val))
+					keyParts = append(keyParts, fmt.Sprintf("%s:%v", col, rules.keyValue(col, val)))
 				}
 			}
 		}
@@ -244,10 +458,10 @@ func (c *Comparator) getRowKey(row models.TableRow, criteria *models.MatchCriter
 		for col, val := range row {
 			if !excludeMap[col] {
 				// Skip primary key columns unless explicitly included
-				if c.isPrimaryKeyColumn(col) && !criteria.IncludePrimaryKey {
+				if rules.isPrimaryKey(col) && !criteria.IncludePrimaryKey {
 					continue
 				}
-				keyParts = append(keyParts, fmt.Sprintf("%s:%v", col, val))
+				keyParts = append(keyParts, fmt.Sprintf("%s:%v", col, rules.keyValue(col, val)))
 			}
 		}
 	}
@@ -257,23 +471,8 @@ func (c *Comparator) getRowKey(row models.TableRow, criteria *models.MatchCriter
 	return strings.Join(keyParts, "|")
 }
 
-// getRowIdentifier creates a human-readable identifier for a row
-func (c *Comparator) getRowIdentifier(row models.TableRow, criteria *models.MatchCriteria) string {
-	return c.getRowKey(row, criteria)
-}
-
-// compareRows compares two rows and returns differences
-func (c *Comparator) compareRows(row1, row2 models.TableRow, criteria *models.MatchCriteria) *models.RowDifference {
-	return c.compareRowsWithFK(row1, row2, criteria, nil)
-}
-
-func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *models.MatchCriteria, foreignKeys []models.ForeignKey) *models.RowDifference {
-	diff := &models.RowDifference{
-		DB1Row:            row1,
-		DB2Row:            row2,
-		ColumnDifferences: []models.ColumnDifference{},
-	}
-
+// buildExcludeMap returns the set of columns excluded by the criteria, including the exclude file
+func (c *Comparator) buildExcludeMap(criteria *models.MatchCriteria) map[string]bool {
 	excludeMap := make(map[string]bool)
 	for _, col := range criteria.ExcludeColumns {
 		excludeMap[col] = true
@@ -290,10 +489,113 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 		}
 	}
 
+	return excludeMap
+}
+
+// matchKeyColumns returns the columns getRowKey uses to build the match key, in a stable order
+func (c *Comparator) matchKeyColumns(columns []string, criteria *models.MatchCriteria, rules *columnRules) []string {
+	if len(criteria.KeyColumns) > 0 {
+		return append([]string{}, criteria.KeyColumns...)
+	}
+
+	excludeMap := c.buildExcludeMap(criteria)
+
+	available := make(map[string]bool)
+	for _, col := range columns {
+		available[col] = true
+	}
+
+	var keyColumns []string
+	if len(criteria.Columns) > 0 {
+		for _, col := range criteria.Columns {
+			if !excludeMap[col] && available[col] {
+				keyColumns = append(keyColumns, col)
+			}
+		}
+	} else {
+		for _, col := range columns {
+			if excludeMap[col] {
+				continue
+			}
+			if rules.isPrimaryKey(col) && !criteria.IncludePrimaryKey {
+				continue
+			}
+			keyColumns = append(keyColumns, col)
+		}
+	}
+
+	sort.Strings(keyColumns)
+	return keyColumns
+}
+
+// comparedColumns returns, in a stable order, the columns whose values are compared between matched rows.
+// With an explicit business key, key columns are equal by construction and -include narrows the set.
+func (c *Comparator) comparedColumns(columns []string, criteria *models.MatchCriteria, rules *columnRules) []string {
+	excludeMap := c.buildExcludeMap(criteria)
+
+	keyMap := make(map[string]bool)
+	for _, col := range criteria.KeyColumns {
+		keyMap[col] = true
+	}
+	includeMap := make(map[string]bool)
+	if len(criteria.KeyColumns) > 0 {
+		for _, col := range criteria.Columns {
+			includeMap[col] = true
+		}
+	}
+
+	var compared []string
+	for _, col := range columns {
+		if excludeMap[col] {
+			continue
+		}
+
+		if len(criteria.KeyColumns) > 0 {
+			if keyMap[col] || (len(includeMap) > 0 && !includeMap[col]) {
+				continue
+			}
+			// Skip primary key columns unless explicitly included
+			if rules.isPrimaryKey(col) && !criteria.IncludePrimaryKey {
+				continue
+			}
+		}
+
+		compared = append(compared, col)
+	}
+
+	sort.Strings(compared)
+	return compared
+}
+
+// getRowIdentifier creates a human-readable identifier for a row: its business key when one is
+// given, else its primary key or NOT NULL unique key, else its match key
+func (c *Comparator) getRowIdentifier(row models.TableRow, criteria *models.MatchCriteria, rules *columnRules) string {
+	if len(criteria.KeyColumns) == 0 && len(rules.rowKey) > 0 {
+		return c.getRowKey(row, &models.MatchCriteria{KeyColumns: rules.rowKey}, rules)
+	}
+	return c.getRowKey(row, criteria, rules)
+}
+
+// compareRows compares two rows and returns differences
+func (c *Comparator) compareRows(row1, row2 models.TableRow, criteria *models.MatchCriteria, rules *columnRules) *models.RowDifference {
+	return c.compareRowsWithFK(row1, row2, criteria, rules, nil)
+}
+
+// compareRowsWithFK compares the normalized values of two matched rows and resolves the
+// referenced rows of differing foreign key columns
+func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *models.MatchCriteria, rules *columnRules, tableSchema *models.TableSchema) *models.RowDifference {
+	diff := &models.RowDifference{
+		DB1Row:            row1,
+		DB2Row:            row2,
+		ColumnDifferences: []models.ColumnDifference{},
+	}
+
 	// Create map for quick FK lookup
 	fkMap := make(map[string]models.ForeignKey)
-	for _, fk := range foreignKeys {
-		fkMap[fk.ColumnName] = fk
+	if tableSchema != nil {
+		for _, fk := range tableSchema.ForeignKeys {
+			fkMap[fk.ColumnName] = fk
+		}
 	}
 
 	// Compare all columns
@@ -305,11 +607,12 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 		allColumns[col] = true
 	}
 
+	columns := make([]string, 0, len(allColumns))
 	for col := range allColumns {
-		if excludeMap[col] {
-			continue
-		}
+		columns = append(columns, col)
+	}
 
+	for _, col := range c.comparedColumns(columns, criteria, rules) {
 		val1, exists1 := row1[col]
 		val2, exists2 := row2[col]
 
@@ -317,7 +620,25 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 			continue
 		}
 
-		if !exists1 || !exists2 || !reflect.DeepEqual(val1, val2) {
+		if exists1 && exists2 && rules.nullEquivalent(col, val1, val2) {
+			// NULL equivalents count as equal and are only listed by name
+			diff.NullEquivalentColumns = append(diff.NullEquivalentColumns, col)
+			continue
+		}
+
+		if exists1 && exists2 && !rules.equal(col, val1, val2) && rules.withinTolerance(col, val1, val2) {
+			// Tolerated differences count as equal and are only kept when asked for
+			if criteria.ReportWithinTolerance {
+				diff.ToleratedDifferences = append(diff.ToleratedDifferences, models.ColumnDifference{
+					ColumnName: col,
+					DB1Value:   convertBytesToString(val1),
+					DB2Value:   convertBytesToString(val2),
+				})
+			}
+			continue
+		}
+
+		if !exists1 || !exists2 || !rules.equal(col, val1, val2) {
 			// Convert byte arrays to strings for consistent processing
 			db1Value := convertBytesToString(val1)
 			db2Value := convertBytesToString(val2)
@@ -326,6 +647,8 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 				ColumnName: col,
 				DB1Value:   db1Value,
 				DB2Value:   db2Value,
+				JSONDiff:   rules.jsonDiff(col, val1, val2),
+				ArrayDiff:  rules.arrayDiff(col, val1, val2),
 			}
 
 			// Check if this column is a foreign key
@@ -334,7 +657,7 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 
 				// Get referenced data if values exist
 				if val1 != nil || val2 != nil {
-					fkRef := c.getForeignKeyReference(fk, val1, val2)
+					fkRef := c.getForeignKeyReference(fk, val1, val2, tableSchema, criteria)
 					colDiff.ForeignKeyReference = fkRef
 				}
 			}
@@ -347,7 +670,7 @@ func (c *Comparator) compareRowsWithFK(row1, row2 models.TableRow, criteria *mod
 }
 
 // getForeignKeyReference gets the referenced data for a foreign key
-func (c *Comparator) getForeignKeyReference(fk models.ForeignKey, val1, val2 interface{}) *models.ForeignKeyReference {
+func (c *Comparator) getForeignKeyReference(fk models.ForeignKey, val1, val2 interface{}, tableSchema *models.TableSchema, criteria *models.MatchCriteria) *models.ForeignKeyReference {
 	var values []interface{}
 	if val1 != nil {
 		values = append(values, val1)
@@ -361,8 +684,10 @@ func (c *Comparator) getForeignKeyReference(fk models.ForeignKey, val1, val2 int
 	}
 
 	// Get foreign key data from both databases
+	fk2, referenced2 := referencedTableInDB2(fk, tableSchema.Schema, tableSchema.TableName, criteria)
 	fkData1, err1 := c.DB1.GetForeignKeyData(fk, values)
-	fkData2, err2 := c.DB2.GetForeignKeyData(fk, values)
+	fkData2, err2 := c.DB2.GetForeignKeyData(fk2, values)
+	fkData2 = referenced2.CanonicalRows(fkData2)
 
 	if err1 != nil && err2 != nil {
 		return nil
@@ -401,8 +726,11 @@ func (c *Comparator) getForeignKeyReference(fk models.ForeignKey, val1, val2 int
 	return fkRef
 }
 
-// compareForeignKey compares foreign key relationships
-func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *models.TableData, criteria *models.MatchCriteria) *models.ForeignKeyResult {
+// compareForeignKey compares foreign key relationships. The rows referenced from data1 and data2
+// are compared at the given depth, leaving out those already compared in the traversal, and the
+// foreign keys of all of them are followed while the traversal depth allows.
+func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *models.TableData, traversal *fkTraversal, depth int) *models.ForeignKeyResult {
+	criteria := traversal.criteria
 	result := &models.ForeignKeyResult{
 		ForeignKey:   fk,
 		FKReferences: []models.ForeignKeyReference{},
@@ -423,15 +751,10 @@ func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *model
 		}
 	}
 
-	// Get all unique values for comparison
-	allValues := c.getUniqueValues(append(fkValues1, fkValues2...))
-
-	// Get foreign key data from both databases
-	fkData1, err1 := c.DB1.GetForeignKeyData(fk, allValues)
-	fkData2, err2 := c.DB2.GetForeignKeyData(fk, allValues)
-
-	if err1 != nil || err2 != nil {
-		result.Error = fmt.Sprintf("Error getting foreign key data: DB1=%v, DB2=%v", err1, err2)
+	// Get the referenced rows of all unique values, fetching those not seen yet from both databases
+	entries, err := c.referencedRows(fk, c.getUniqueValues(append(fkValues1, fkValues2...)), traversal)
+	if err != nil {
+		result.Error = fmt.Sprintf("Error getting foreign key data: %v", err)
 		return result
 	}
 
@@ -439,21 +762,38 @@ func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *model
 	tempData1 := &models.TableData{
 		TableName: fk.ReferencedTable,
 		Schema:    fk.ReferencedSchema,
-		Rows:      fkData1,
+		Rows:      []models.TableRow{},
 	}
 
 	tempData2 := &models.TableData{
 		TableName: fk.ReferencedTable,
 		Schema:    fk.ReferencedSchema,
-		Rows:      fkData2,
+		Rows:      []models.TableRow{},
+	}
+
+	// All rows are followed further, but rows already compared through another foreign key are
+	// left out of this comparison
+	var compareRows1, compareRows2 []models.TableRow
+	for _, entry := range entries {
+		tempData1.Rows = append(tempData1.Rows, entry.rows1...)
+		tempData2.Rows = append(tempData2.Rows, entry.rows2...)
+		if entry.compared {
+			result.NestedElsewhere = true
+			continue
+		}
+		entry.compared = true
+		compareRows1 = append(compareRows1, entry.rows1...)
+		compareRows2 = append(compareRows2, entry.rows2...)
 	}
 
 	// Get schema for the referenced table to create appropriate criteria
 	referencedSchema, err := c.DB1.GetTableSchema(fk.ReferencedSchema, fk.ReferencedTable)
 	var fkCriteria *models.MatchCriteria
 	if err != nil {
-		// If we can't get schema, use the provided criteria
-		fkCriteria = criteria
+		// If we can't get schema, use the provided criteria without the business key of the compared table
+		fallback := *criteria
+		fallback.KeyColumns = nil
+		fkCriteria = &fallback
 	} else {
 		// Create default criteria for the referenced table
 		fkCriteria = c.createDefaultMatchCriteria(referencedSchema)
@@ -463,28 +803,30 @@ func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *model
 	}
 
 	// Compare the foreign key data directly using row matching with appropriate criteria
-	matches, onlyInDB1, onlyInDB2 := c.matchRows(tempData1.Rows, tempData2.Rows, fkCriteria)
+	fkRules := newColumnRules(referencedSchema, fkCriteria)
+	matches, onlyInDB1, onlyInDB2, duplicates := c.matchRows(compareRows1, compareRows2, fkCriteria, fkRules)
 
 	fkComparison := &models.ComparisonResult{
 		TableName:     fk.ReferencedTable,
 		Schema:        fk.ReferencedSchema,
 		Timestamp:     time.Now(),
-		TotalRowsDB1:  len(tempData1.Rows),
-		TotalRowsDB2:  len(tempData2.Rows),
+		TotalRowsDB1:  len(compareRows1),
+		TotalRowsDB2:  len(compareRows2),
 		MatchedRows:   len(matches),
 		UnmatchedRows: len(onlyInDB1) + len(onlyInDB2),
 		OnlyInDB1:     onlyInDB1,
 		OnlyInDB2:     onlyInDB2,
 		Differences:   []models.RowDifference{},
+		DuplicateKeys: duplicates,
 	}
 
 	// Check for differences in matched foreign key rows and build FK references
 	for _, match := range matches {
-		diff := c.compareRows(match.row1, match.row2, criteria)
+		diff := c.compareRows(match.row1, match.row2, fkCriteria, fkRules)
 		hasDiff := len(diff.ColumnDifferences) > 0
 
 		if hasDiff {
-			diff.RowIdentifier = c.getRowIdentifier(match.row1, criteria)
+			diff.RowIdentifier = c.getRowIdentifier(match.row1, fkCriteria, fkRules)
 			fkComparison.Differences = append(fkComparison.Differences, *diff)
 		}
 
@@ -519,22 +861,20 @@ func (c *Comparator) compareForeignKey(fk models.ForeignKey, data1, data2 *model
 	}
 
 	result.ComparisonResult = *fkComparison
+
+	c.followNested(result, referencedSchema, tempData1, tempData2, entries, traversal, depth)
 	return result
 }
 
-// createDefaultMatchCriteria creates default matching criteria based on table schema
+// createDefaultMatchCriteria creates default matching criteria based on table schema.
+// Rows are paired on the natural key when the table has one, otherwise on all non-key columns.
 func (c *Comparator) createDefaultMatchCriteria(schema *models.TableSchema) *models.MatchCriteria {
-	var excludeColumns []string
-
 	// Exclude primary key columns by default (IDs that might differ)
-	for _, col := range schema.Columns {
-		if col.IsPrimary {
-			excludeColumns = append(excludeColumns, col.ColumnName)
-		}
-	}
+	excludeColumns := append([]string{}, schema.PrimaryKey...)
 
 	return &models.MatchCriteria{
-		Columns:                []string{}, // Empty means use all columns
+		KeyColumns:             schema.NaturalKey(), // Pair rows on a NOT NULL unique key when the table has one
+		Columns:                []string{},          // Empty means use all columns
 		ExcludeColumns:         excludeColumns,
 		IncludePrimaryKey:      false,
 		ExcludeColumnsFromFile: true,                  // Enable file column exclusion by default
@@ -542,12 +882,6 @@ func (c *Comparator) createDefaultMatchCriteria(schema *models.TableSchema) *mod
 	}
 }
 
-// isPrimaryKeyColumn checks if a column is a primary key (simple heuristic)
-func (c *Comparator) isPrimaryKeyColumn(columnName string) bool {
-	// Simple heuristic - can be improved with schema information
-	return columnName == "id" || columnName == "ID"
-}
-
 // getUniqueValues returns unique values from a slice
 func (c *Comparator) getUniqueValues(values []interface{}) []interface{} {
 	var unique []interface{}
@@ -802,8 +1136,8 @@ func (c *Comparator) AnalyzeFKReferences(schema, tableName, targetID string) (*m
 	return result, nil
 }
 
-// discoverFKConstraints finds all foreign key constraints that reference a specific table
-func (c *Comparator) discoverFKConstraints(schema, tableName string) ([]models.FKTableReference, error) {
+// discoverFKConstraints finds all foreign key constraints that reference a specific column of a table
+func (c *Comparator) discoverFKConstraints(schema, tableName, keyColumn string) ([]models.FKTableReference, error) {
 	// Query to find all formal foreign key constraints that reference the target table
 	fkQuery := `
 		SELECT 
@@ -821,10 +1155,11 @@ func (c *Comparator) discoverFKConstraints(schema, tableName string) ([]models.F
 		WHERE tc.constraint_type = 'FOREIGN KEY' 
 			AND ccu.table_schema = $1 
 			AND ccu.table_name = $2
+			AND ccu.column_name = $3
 		ORDER BY tc.table_schema, tc.table_name, kcu.column_name`
 
 	// Use DB1 for schema information (both DBs should have same structure)
-	rows, err := c.DB1.DB.Query(fkQuery, schema, tableName)
+	rows, err := c.DB1.DB.Query(fkQuery, schema, tableName, keyColumn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
 	}
@@ -901,8 +1236,19 @@ func (c *Comparator) discoverFKConstraints(schema, tableName string) ([]models.F
 // GenerateUpdateScript generates SQL script to update foreign key references from idTarget to idDestination
 // and then delete the original record
 func (c *Comparator) GenerateUpdateScript(schema, tableName, idTarget, idDestination string) (string, error) {
+	// The record is addressed by its primary key, or by a NOT NULL unique key when there is none
+	tableSchema, err := c.DB1.GetTableSchema(schema, tableName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get table schema: %w", err)
+	}
+	rowKey := tableSchema.RowKey()
+	if len(rowKey) != 1 {
+		return "", fmt.Errorf("%s.%s needs a single-column primary key or NOT NULL unique key to generate an update script", schema, tableName)
+	}
+	keyColumn := rowKey[0]
+
 	// Discover FK constraints pointing to this table
-	fkConstraints, err := c.discoverFKConstraints(schema, tableName)
+	fkConstraints, err := c.discoverFKConstraints(schema, tableName, keyColumn)
 	if err != nil {
 		return "", fmt.Errorf("failed to discover FK constraints: %w", err)
 	}
@@ -912,6 +1258,7 @@ func (c *Comparator) GenerateUpdateScript(schema, tableName, idTarget, idDestina
 	// Header
 	script.WriteString("-- Generated FK Update Script\n")
 	script.WriteString(fmt.Sprintf("-- Target table: %s.%s\n", schema, tableName))
+	script.WriteString(fmt.Sprintf("-- Key column: %s\n", keyColumn))
 	script.WriteString(fmt.Sprintf("-- Update FK references from ID %s to ID %s\n", idTarget, idDestination))
 	script.WriteString(fmt.Sprintf("-- Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
 	script.WriteString("-- WARNING: Review this script before execution!\n")
@@ -937,7 +1284,7 @@ func (c *Comparator) GenerateUpdateScript(schema, tableName, idTarget, idDestina
 
 	// Delete original record
 	script.WriteString("-- Delete original record\n")
-	deleteSQL := fmt.Sprintf("DELETE FROM %s.%s WHERE id = %s;", schema, tableName, idTarget)
+	deleteSQL := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = %s;", schema, tableName, keyColumn, idTarget)
 	script.WriteString(deleteSQL + "\n")
 
 	script.WriteString("\n")
@@ -0,0 +1,139 @@
+package comparator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+)
+
+// CompareEntity compares a single row of a table between both databases, found by its key values,
+// together with its neighbouring rows: the rows it references through its foreign keys (followed
+// up to FKDepth hops) and the rows of the tables referencing its primary key (limited to
+// ChildTables when set). The key is the business key of the criteria when set, else the primary
+// key or a NOT NULL unique key; keyValues hold one value per key column, in order. Referencing
+// rows are only looked up for a single-column primary key, and a table referencing itself is not
+// followed in that direction.
+func (c *Comparator) CompareEntity(schema, tableName string, keyValues []string, criteria *models.MatchCriteria) (*models.EntityComparisonResult, error) {
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, _, err := c.loadTableSchemas(target1, target2)
+	if err != nil {
+		return nil, err
+	}
+
+	if criteria == nil {
+		criteria = c.createDefaultMatchCriteria(schema1)
+	}
+
+	if err := validateCriteria(schema1, criteria); err != nil {
+		return nil, err
+	}
+
+	keyCriteria, err := keyedCriteria(schema1, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("entity comparison: %w", err)
+	}
+	keyColumns := keyCriteria.KeyColumns
+	if len(keyValues) != len(keyColumns) {
+		return nil, fmt.Errorf("entity comparison: %d key values given for key columns %s", len(keyValues), strings.Join(keyColumns, ", "))
+	}
+
+	lookupProgress := progress.NewSimpleProgress(fmt.Sprintf("Looking up %s.%s %s", schema, tableName, strings.Join(keyValues, ", ")))
+	row1, err := findEntityRow(c.DB1, target1, keyColumns, keyValues, "DB1")
+	if err != nil {
+		return nil, err
+	}
+	row2, err := findEntityRow(c.DB2, target2, keyColumns, keyValues, "DB2")
+	if err != nil {
+		return nil, err
+	}
+	if row1 == nil && row2 == nil {
+		return nil, fmt.Errorf("no row of %s.%s has %s = %s in either database", schema, tableName,
+			strings.Join(keyColumns, ", "), strings.Join(keyValues, ", "))
+	}
+	lookupProgress.Finish("Found the row")
+
+	result := &models.EntityComparisonResult{
+		Schema:     schema,
+		TableName:  tableName,
+		Timestamp:  time.Now(),
+		KeyColumns: keyColumns,
+		KeyValues:  keyValues,
+		DB1Row:     row1,
+		DB2Row:     row2,
+		Outgoing:   []models.ForeignKeyResult{},
+		Incoming:   []models.ChildDifference{},
+	}
+	if target2.Schema != target1.Schema {
+		result.DB2Schema = target2.Schema
+	}
+	if target2.Table != target1.Table {
+		result.DB2TableName = target2.Table
+	}
+
+	switch {
+	case row2 == nil:
+		result.Status = models.EntityStatusOnlyInDB1
+	case row1 == nil:
+		result.Status = models.EntityStatusOnlyInDB2
+	default:
+		rules := newColumnRules(schema1, criteria)
+		diff := c.compareRowsWithFK(row1, row2, criteria, rules, schema1)
+		result.Status = models.EntityStatusIdentical
+		if len(diff.ColumnDifferences) > 0 {
+			diff.RowIdentifier = c.getRowIdentifier(row1, keyCriteria, rules)
+			result.Difference = diff
+			result.Status = models.EntityStatusDifferent
+		}
+	}
+
+	// Outgoing references: the rows the entity points to in each database
+	data1 := &models.TableData{TableName: tableName, Schema: schema, Rows: entityRows(row1)}
+	data2 := &models.TableData{TableName: tableName, Schema: schema, Rows: entityRows(row2)}
+	traversal := newFKTraversal(schema, tableName, criteria)
+	for _, fk := range schema1.ForeignKeys {
+		fkResult := c.compareForeignKey(fk, data1, data2, traversal, 1)
+		result.Outgoing = append(result.Outgoing, *fkResult)
+	}
+
+	// Incoming references: the rows pointing to the entity in each database
+	if len(schema1.PrimaryKey) == 1 {
+		children, err := c.loadChildTables(schema1, []rowMatch{{row1: row1, row2: row2}}, criteria)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			result.Incoming = append(result.Incoming, c.compareChildTable(child, row1, row2))
+		}
+	}
+
+	return result, nil
+}
+
+// findEntityRow looks up the row with the given key values in one database. It returns nil when
+// the row does not exist there, and an error when the key matches several rows.
+func findEntityRow(conn *database.Connection, target models.TableTarget, keyColumns, keyValues []string, dbName string) (models.TableRow, error) {
+	rows, err := conn.GetRowsByKey(target.Schema, target.Table, target.Where, columnRefs(keyColumns, target), [][]string{keyValues})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the row in %s: %w", dbName, err)
+	}
+	if len(rows) > 1 {
+		return nil, fmt.Errorf("%s.%s has %d rows with %s = %s in %s; use -key with unique columns",
+			target.Schema, target.Table, len(rows), strings.Join(keyColumns, ", "), strings.Join(keyValues, ", "), dbName)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return target.CanonicalRows(rows)[0], nil
+}
+
+// entityRows returns the row of the entity in one database as table rows, none when it is missing
+func entityRows(row models.TableRow) []models.TableRow {
+	if row == nil {
+		return []models.TableRow{}
+	}
+	return []models.TableRow{row}
+}
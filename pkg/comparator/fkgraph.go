@@ -0,0 +1,134 @@
+package comparator
+
+import (
+	"fmt"
+
+	"deepComparator/pkg/models"
+)
+
+// fkTraversal is the state shared by the foreign key comparisons of one compared table. The
+// referenced rows fetched from both databases are cached by table, column and value, so that each
+// row is fetched and compared once however many foreign keys or paths lead to it, together with
+// the number of hops already followed from it. The tables on the current path are tracked so that cycles are
+// not followed.
+type fkTraversal struct {
+	schema    string // compared table, whose DB2 name and column map references follow
+	tableName string
+	criteria  *models.MatchCriteria
+	maxDepth  int
+	path      map[string]bool
+	rows      map[string]*fkCachedRows
+}
+
+// fkCachedRows are the rows of both databases holding one value of a referenced column, whether
+// they have been compared, and the number of hops their foreign keys have been followed
+type fkCachedRows struct {
+	rows1    []models.TableRow
+	rows2    []models.TableRow
+	compared bool
+	followed int
+}
+
+// newFKTraversal starts the foreign key traversal of a compared table
+func newFKTraversal(schema, tableName string, criteria *models.MatchCriteria) *fkTraversal {
+	maxDepth := 1
+	if criteria != nil && criteria.FKDepth > 1 {
+		maxDepth = criteria.FKDepth
+	}
+
+	return &fkTraversal{
+		schema:    schema,
+		tableName: tableName,
+		criteria:  criteria,
+		maxDepth:  maxDepth,
+		path:      map[string]bool{schema + "." + tableName: true},
+		rows:      make(map[string]*fkCachedRows),
+	}
+}
+
+// fkValueKey returns the cache key of a value of the column referenced by a foreign key
+func fkValueKey(fk models.ForeignKey, value interface{}) string {
+	return fmt.Sprintf("%s.%s.%s=%v", fk.ReferencedSchema, fk.ReferencedTable, fk.ReferencedColumnName, convertBytesToString(value))
+}
+
+// referencedRows returns the cached rows of both databases referenced by the given values,
+// fetching the values seen for the first time
+func (c *Comparator) referencedRows(fk models.ForeignKey, values []interface{}, traversal *fkTraversal) ([]*fkCachedRows, error) {
+	var missing []interface{}
+	for _, val := range values {
+		if _, cached := traversal.rows[fkValueKey(fk, val)]; !cached {
+			missing = append(missing, val)
+		}
+	}
+
+	if len(missing) > 0 {
+		fk2, referenced2 := referencedTableInDB2(fk, traversal.schema, traversal.tableName, traversal.criteria)
+		fetched1, err1 := c.DB1.GetForeignKeyData(fk, missing)
+		fetched2, err2 := c.DB2.GetForeignKeyData(fk2, missing)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("DB1=%v, DB2=%v", err1, err2)
+		}
+
+		for _, val := range missing {
+			traversal.rows[fkValueKey(fk, val)] = &fkCachedRows{}
+		}
+		for _, row := range fetched1 {
+			if entry, exists := traversal.rows[fkValueKey(fk, row[fk.ReferencedColumnName])]; exists {
+				entry.rows1 = append(entry.rows1, row)
+			}
+		}
+		for _, row := range referenced2.CanonicalRows(fetched2) {
+			if entry, exists := traversal.rows[fkValueKey(fk, row[fk.ReferencedColumnName])]; exists {
+				entry.rows2 = append(entry.rows2, row)
+			}
+		}
+	}
+
+	var entries []*fkCachedRows
+	seen := make(map[string]bool)
+	for _, val := range values {
+		key := fkValueKey(fk, val)
+		if !seen[key] {
+			seen[key] = true
+			entries = append(entries, traversal.rows[key])
+		}
+	}
+	return entries, nil
+}
+
+// followNested compares the foreign keys of a referenced table over its rows fetched from both
+// databases, unless the depth is reached or the table is already on the current path. When the
+// foreign keys of all the rows were already followed at least as many hops through another
+// foreign key, they are not followed again and NestedElsewhere is set instead.
+func (c *Comparator) followNested(result *models.ForeignKeyResult, referencedSchema *models.TableSchema, data1, data2 *models.TableData, entries []*fkCachedRows, traversal *fkTraversal, depth int) {
+	remaining := traversal.maxDepth - depth
+	if remaining <= 0 || referencedSchema == nil {
+		return
+	}
+
+	table := data1.Schema + "." + data1.TableName
+	if traversal.path[table] {
+		result.CycleDetected = true
+		return
+	}
+
+	deeper := len(entries) == 0
+	for _, entry := range entries {
+		if entry.followed < remaining {
+			entry.followed = remaining
+			deeper = true
+		}
+	}
+	if !deeper {
+		result.NestedElsewhere = true
+		return
+	}
+
+	traversal.path[table] = true
+	defer delete(traversal.path, table)
+
+	for _, fk := range referencedSchema.ForeignKeys {
+		nested := c.compareForeignKey(fk, data1, data2, traversal, depth+1)
+		result.Nested = append(result.Nested, *nested)
+	}
+}
@@ -0,0 +1,106 @@
+package comparator
+
+import (
+	"fmt"
+	"sort"
+
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+)
+
+// maxProbableMatchComparisons bounds the row pairs scored when looking for probable matches
+const maxProbableMatchComparisons = 10000000
+
+// probableCandidate is a scored pair of unmatched rows
+type probableCandidate struct {
+	index1, index2 int
+	confidence     float64
+}
+
+// findProbableMatches pairs rows found only in DB1 with rows found only in DB2 that are likely
+// the same row modified in a matched column. Every cross pair is scored by the share of columns
+// with equal values; pairs reaching the threshold are accepted greedily, best score first, so
+// that each row takes part in at most one probable match. Primary key columns are not scored
+// unless included by the criteria, but differences in them are still listed.
+func (c *Comparator) findProbableMatches(onlyInDB1, onlyInDB2 []models.TableRow, criteria *models.MatchCriteria, rules *columnRules) []models.ProbableMatch {
+	if criteria.ProbableMatchThreshold <= 0 || len(onlyInDB1) == 0 || len(onlyInDB2) == 0 {
+		return nil
+	}
+
+	if len(onlyInDB1)*len(onlyInDB2) > maxProbableMatchComparisons {
+		fmt.Printf("Warning: Skipping probable match search, %d x %d unmatched rows is too many to score\n",
+			len(onlyInDB1), len(onlyInDB2))
+		return nil
+	}
+
+	// Rows are compared on every column, including the match key
+	fuzzyCriteria := *criteria
+	fuzzyCriteria.KeyColumns = nil
+	fuzzyCriteria.Columns = nil
+
+	allColumns := make(map[string]bool)
+	for _, row := range []models.TableRow{onlyInDB1[0], onlyInDB2[0]} {
+		for col := range row {
+			allColumns[col] = true
+		}
+	}
+	columns := make([]string, 0, len(allColumns))
+	for col := range allColumns {
+		columns = append(columns, col)
+	}
+
+	var scored []string
+	for _, col := range c.comparedColumns(columns, &fuzzyCriteria, rules) {
+		if rules.isPrimaryKey(col) && !criteria.IncludePrimaryKey {
+			continue
+		}
+		scored = append(scored, col)
+	}
+	if len(scored) == 0 {
+		return nil
+	}
+
+	scoreProgress := progress.NewSimpleProgress("Scoring unmatched rows for probable matches")
+	var candidates []probableCandidate
+	for i, row1 := range onlyInDB1 {
+		for j, row2 := range onlyInDB2 {
+			equal := 0
+			for _, col := range scored {
+				if rules.equal(col, row1[col], row2[col]) || rules.nullEquivalent(col, row1[col], row2[col]) {
+					equal++
+				}
+			}
+
+			confidence := float64(equal) / float64(len(scored))
+			if confidence >= criteria.ProbableMatchThreshold {
+				candidates = append(candidates, probableCandidate{index1: i, index2: j, confidence: confidence})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].confidence > candidates[b].confidence
+	})
+
+	paired1 := make(map[int]bool)
+	paired2 := make(map[int]bool)
+	var matches []models.ProbableMatch
+	for _, candidate := range candidates {
+		if paired1[candidate.index1] || paired2[candidate.index2] {
+			continue
+		}
+		paired1[candidate.index1] = true
+		paired2[candidate.index2] = true
+
+		row1 := onlyInDB1[candidate.index1]
+		diff := c.compareRows(row1, onlyInDB2[candidate.index2], &fuzzyCriteria, rules)
+		diff.RowIdentifier = c.getRowIdentifier(row1, criteria, rules)
+		matches = append(matches, models.ProbableMatch{
+			Confidence:    candidate.confidence,
+			RowDifference: *diff,
+		})
+	}
+	scoreProgress.Finish(fmt.Sprintf("Found %d probable matches", len(matches)))
+
+	return matches
+}
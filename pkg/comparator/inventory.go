@@ -0,0 +1,98 @@
+package comparator
+
+import (
+	"fmt"
+	"time"
+
+	"deepComparator/pkg/models"
+)
+
+// InventoryObjectTypes lists the types of database objects of the inventory comparison, in report order
+var InventoryObjectTypes = []string{
+	"table",
+	"view",
+	"materialized view",
+	"sequence",
+	"function",
+	"type",
+	"extension",
+}
+
+// CompareInventory compares the object inventories of the two databases: the tables, views,
+// materialized views, sequences, functions, types and extensions that exist in only one of them,
+// and those whose definition hashes differ. Schemas limits the inventory; empty means all schemas.
+func (c *Comparator) CompareInventory(schemas []string) (*models.InventoryComparisonResult, error) {
+	objects1, err := c.DB1.GetObjectInventory(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB1 inventory: %w", err)
+	}
+
+	objects2, err := c.DB2.GetObjectInventory(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB2 inventory: %w", err)
+	}
+
+	result := diffInventories(objects1, objects2)
+	result.Schemas = schemas
+	return result, nil
+}
+
+// diffInventories compares two object inventories, pairing objects by type, schema and name
+func diffInventories(objects1, objects2 []models.DatabaseObject) *models.InventoryComparisonResult {
+	result := &models.InventoryComparisonResult{
+		Timestamp:             time.Now(),
+		Counts:                []models.InventoryCount{},
+		OnlyInDB1:             []models.DatabaseObject{},
+		OnlyInDB2:             []models.DatabaseObject{},
+		DefinitionDifferences: []models.InventoryDifference{},
+	}
+
+	counts1 := make(map[string]int)
+	counts2 := make(map[string]int)
+
+	byKey2 := make(map[string]models.DatabaseObject)
+	for _, object := range objects2 {
+		byKey2[object.Key()] = object
+		counts2[object.ObjectType]++
+	}
+
+	paired := make(map[string]bool)
+	for _, object1 := range objects1 {
+		counts1[object1.ObjectType]++
+
+		object2, exists := byKey2[object1.Key()]
+		if !exists {
+			result.OnlyInDB1 = append(result.OnlyInDB1, object1)
+			continue
+		}
+		paired[object1.Key()] = true
+
+		if object1.DefinitionHash != object2.DefinitionHash {
+			result.DefinitionDifferences = append(result.DefinitionDifferences, models.InventoryDifference{
+				ObjectType: object1.ObjectType,
+				Schema:     object1.Schema,
+				Name:       object1.Name,
+				DB1Hash:    object1.DefinitionHash,
+				DB2Hash:    object2.DefinitionHash,
+				DB1Version: object1.Version,
+				DB2Version: object2.Version,
+			})
+		}
+	}
+
+	for _, object2 := range objects2 {
+		if !paired[object2.Key()] {
+			result.OnlyInDB2 = append(result.OnlyInDB2, object2)
+		}
+	}
+
+	for _, objectType := range InventoryObjectTypes {
+		result.Counts = append(result.Counts, models.InventoryCount{
+			ObjectType: objectType,
+			DB1:        counts1[objectType],
+			DB2:        counts2[objectType],
+		})
+	}
+
+	return result
+}
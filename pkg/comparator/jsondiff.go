@@ -0,0 +1,410 @@
+package comparator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"deepComparator/pkg/models"
+)
+
+// JSON path change kinds
+const (
+	JSONChanged   = "changed"
+	JSONAdded     = "added"
+	JSONRemoved   = "removed"
+	JSONReordered = "reordered"
+)
+
+// jsonObject is a decoded JSON object that remembers the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// jsonPathSegment matches one step of a JSON path: .key, [index] or [*]
+var jsonPathSegment = regexp.MustCompile(`\.([^.\[]+)|\[(\d+|\*)\]`)
+
+// jsonOptions controls how JSON values of a column are compared
+type jsonOptions struct {
+	ignoreKeyOrder  bool
+	unorderedArrays bool
+	ignorePaths     [][]string
+}
+
+// isJSONType reports whether a PostgreSQL data type holds JSON documents
+func isJSONType(dataType string) bool {
+	return dataType == "json" || dataType == "jsonb"
+}
+
+// parseJSONPath splits a path such as $.items[*].price into segments (.items, [*], .price)
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path %q must start with $", path)
+	}
+
+	rest := path[1:]
+	matches := jsonPathSegment.FindAllStringIndex(rest, -1)
+	var segments []string
+	consumed := 0
+	for _, m := range matches {
+		if m[0] != consumed {
+			break
+		}
+		segments = append(segments, rest[m[0]:m[1]])
+		consumed = m[1]
+	}
+	if consumed != len(rest) {
+		return nil, fmt.Errorf("invalid JSON path %q", path)
+	}
+
+	return segments, nil
+}
+
+// jsonOptionsFor returns the JSON comparison options of a column. Ignored paths are given either
+// as $.path for every JSON column or as column:$.path for a single one.
+func jsonOptionsFor(col string, criteria *models.MatchCriteria) jsonOptions {
+	options := jsonOptions{
+		ignoreKeyOrder:  criteria.JSONIgnoreKeyOrder,
+		unorderedArrays: criteria.JSONUnorderedArrays,
+	}
+
+	for _, entry := range criteria.JSONIgnorePaths {
+		path := entry
+		if idx := strings.Index(entry, ":"); idx > 0 && !strings.HasPrefix(entry, "$") {
+			if entry[:idx] != col {
+				continue
+			}
+			path = entry[idx+1:]
+		}
+
+		// Invalid paths are rejected by validateCriteria
+		if segments, err := parseJSONPath(path); err == nil {
+			options.ignorePaths = append(options.ignorePaths, segments)
+		}
+	}
+
+	return options
+}
+
+// ignored reports whether the value at the given path is excluded from the comparison
+func (o jsonOptions) ignored(path []string) bool {
+	for _, pattern := range o.ignorePaths {
+		if len(pattern) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range pattern {
+			if segment == path[i] || segment == ".*" || (segment == "[*]" && strings.HasPrefix(path[i], "[")) {
+				continue
+			}
+			matched = false
+			break
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOrderedJSON decodes a JSON document keeping object key order and number literals
+func parseOrderedJSON(data string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return value, nil
+}
+
+// decodeJSONValue reads the next JSON value from a token stream
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := &jsonObject{values: make(map[string]interface{})}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid JSON object key %v", keyToken)
+			}
+
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := obj.values[key]; !exists {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	}
+
+	if number, ok := token.(json.Number); ok && !strings.ContainsAny(string(number), "eE") {
+		return json.Number(canonicalNumeric(string(number))), nil
+	}
+	return token, nil
+}
+
+// canonicalJSON encodes a JSON value compactly after applying the comparison options, so that
+// documents the options consider equal encode identically
+func canonicalJSON(value interface{}, path []string, options jsonOptions) string {
+	var buf bytes.Buffer
+	writeCanonicalJSON(&buf, value, path, options)
+	return buf.String()
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value interface{}, path []string, options jsonOptions) {
+	switch v := value.(type) {
+	case *jsonObject:
+		keys := append([]string{}, v.keys...)
+		if options.ignoreKeyOrder {
+			sort.Strings(keys)
+		}
+
+		buf.WriteByte('{')
+		first := true
+		for _, key := range keys {
+			childPath := appendPath(path, "."+key)
+			if options.ignored(childPath) {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			encoded, _ := json.Marshal(key)
+			buf.Write(encoded)
+			buf.WriteByte(':')
+			writeCanonicalJSON(buf, v.values[key], childPath, options)
+		}
+		buf.WriteByte('}')
+
+	case []interface{}:
+		elements := make([]string, 0, len(v))
+		for i, element := range v {
+			childPath := appendPath(path, "["+strconv.Itoa(i)+"]")
+			if options.ignored(childPath) {
+				continue
+			}
+			elements = append(elements, canonicalJSON(element, childPath, options))
+		}
+		if options.unorderedArrays {
+			sort.Strings(elements)
+		}
+		buf.WriteString("[" + strings.Join(elements, ",") + "]")
+
+	default:
+		encoded, _ := json.Marshal(v)
+		buf.Write(encoded)
+	}
+}
+
+// diffJSON returns the paths at which two JSON values differ under the comparison options
+func diffJSON(value1, value2 interface{}, path []string, options jsonOptions) []models.JSONPathDifference {
+	if options.ignored(path) {
+		return nil
+	}
+
+	obj1, isObj1 := value1.(*jsonObject)
+	obj2, isObj2 := value2.(*jsonObject)
+	if isObj1 && isObj2 {
+		return diffJSONObjects(obj1, obj2, path, options)
+	}
+
+	arr1, isArr1 := value1.([]interface{})
+	arr2, isArr2 := value2.([]interface{})
+	if isArr1 && isArr2 {
+		if options.unorderedArrays {
+			return diffJSONSets(arr1, arr2, path, options)
+		}
+		return diffJSONArrays(arr1, arr2, path, options)
+	}
+
+	if canonicalJSON(value1, path, options) == canonicalJSON(value2, path, options) {
+		return nil
+	}
+	return []models.JSONPathDifference{{
+		Path:     formatJSONPath(path),
+		Change:   JSONChanged,
+		DB1Value: plainJSON(value1),
+		DB2Value: plainJSON(value2),
+	}}
+}
+
+func diffJSONObjects(obj1, obj2 *jsonObject, path []string, options jsonOptions) []models.JSONPathDifference {
+	var diffs []models.JSONPathDifference
+
+	for _, key := range obj1.keys {
+		childPath := appendPath(path, "."+key)
+		if options.ignored(childPath) {
+			continue
+		}
+		value2, exists := obj2.values[key]
+		if !exists {
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONRemoved, DB1Value: plainJSON(obj1.values[key])})
+			continue
+		}
+		diffs = append(diffs, diffJSON(obj1.values[key], value2, childPath, options)...)
+	}
+
+	for _, key := range obj2.keys {
+		childPath := appendPath(path, "."+key)
+		if options.ignored(childPath) {
+			continue
+		}
+		if _, exists := obj1.values[key]; !exists {
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONAdded, DB2Value: plainJSON(obj2.values[key])})
+		}
+	}
+
+	if len(diffs) == 0 && !options.ignoreKeyOrder {
+		keys1 := visibleKeys(obj1, path, options)
+		keys2 := visibleKeys(obj2, path, options)
+		if strings.Join(keys1, "\x00") != strings.Join(keys2, "\x00") {
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(path), Change: JSONReordered, DB1Value: keys1, DB2Value: keys2})
+		}
+	}
+
+	return diffs
+}
+
+func diffJSONArrays(arr1, arr2 []interface{}, path []string, options jsonOptions) []models.JSONPathDifference {
+	var diffs []models.JSONPathDifference
+
+	for i := 0; i < len(arr1) || i < len(arr2); i++ {
+		childPath := appendPath(path, "["+strconv.Itoa(i)+"]")
+		switch {
+		case options.ignored(childPath):
+		case i >= len(arr2):
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONRemoved, DB1Value: plainJSON(arr1[i])})
+		case i >= len(arr1):
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONAdded, DB2Value: plainJSON(arr2[i])})
+		default:
+			diffs = append(diffs, diffJSON(arr1[i], arr2[i], childPath, options)...)
+		}
+	}
+
+	return diffs
+}
+
+// diffJSONSets compares arrays as multisets, reporting elements present on one side only
+func diffJSONSets(arr1, arr2 []interface{}, path []string, options jsonOptions) []models.JSONPathDifference {
+	remaining := make(map[string]int)
+	for i, element := range arr2 {
+		childPath := appendPath(path, "["+strconv.Itoa(i)+"]")
+		if !options.ignored(childPath) {
+			remaining[canonicalJSON(element, childPath, options)]++
+		}
+	}
+
+	var diffs []models.JSONPathDifference
+	for i, element := range arr1 {
+		childPath := appendPath(path, "["+strconv.Itoa(i)+"]")
+		if options.ignored(childPath) {
+			continue
+		}
+		encoded := canonicalJSON(element, childPath, options)
+		if remaining[encoded] > 0 {
+			remaining[encoded]--
+			continue
+		}
+		diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONRemoved, DB1Value: plainJSON(element)})
+	}
+
+	for i, element := range arr2 {
+		childPath := appendPath(path, "["+strconv.Itoa(i)+"]")
+		if options.ignored(childPath) {
+			continue
+		}
+		encoded := canonicalJSON(element, childPath, options)
+		if remaining[encoded] > 0 {
+			remaining[encoded]--
+			diffs = append(diffs, models.JSONPathDifference{Path: formatJSONPath(childPath), Change: JSONAdded, DB2Value: plainJSON(element)})
+		}
+	}
+
+	return diffs
+}
+
+// visibleKeys returns the keys of an object that are not ignored, in document order
+func visibleKeys(obj *jsonObject, path []string, options jsonOptions) []string {
+	var keys []string
+	for _, key := range obj.keys {
+		if !options.ignored(appendPath(path, "."+key)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// plainJSON converts a decoded JSON value back into maps and slices for reporting
+func plainJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *jsonObject:
+		plain := make(map[string]interface{}, len(v.keys))
+		for _, key := range v.keys {
+			plain[key] = plainJSON(v.values[key])
+		}
+		return plain
+	case []interface{}:
+		plain := make([]interface{}, len(v))
+		for i, element := range v {
+			plain[i] = plainJSON(element)
+		}
+		return plain
+	}
+	return value
+}
+
+// appendPath returns a new path with one more segment
+func appendPath(path []string, segment string) []string {
+	child := make([]string, len(path), len(path)+1)
+	copy(child, path)
+	return append(child, segment)
+}
+
+// formatJSONPath renders path segments as $.a.b[0]
+func formatJSONPath(path []string) string {
+	return "$" + strings.Join(path, "")
+}
@@ -0,0 +1,313 @@
+package comparator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// triggerStates maps the firing state of a trigger to the ALTER TABLE action that sets it
+var triggerStates = map[string]string{
+	"ENABLED":  "ENABLE TRIGGER",
+	"DISABLED": "DISABLE TRIGGER",
+	"REPLICA":  "ENABLE REPLICA TRIGGER",
+	"ALWAYS":   "ENABLE ALWAYS TRIGGER",
+}
+
+// migrationScript accumulates the statements of a migration, commenting out destructive ones
+// unless they are allowed
+type migrationScript struct {
+	builder          strings.Builder
+	allowDestructive bool
+	statements       int
+	skipped          int
+}
+
+// section starts a group of statements with a comment
+func (m *migrationScript) section(title string) {
+	m.builder.WriteString(fmt.Sprintf("\n-- %s\n", title))
+}
+
+// comment writes a comment line
+func (m *migrationScript) comment(text string) {
+	m.builder.WriteString(fmt.Sprintf("-- %s\n", text))
+}
+
+// statement writes a statement
+func (m *migrationScript) statement(sql string) {
+	m.builder.WriteString(sql + "\n")
+	m.statements++
+}
+
+// destructive writes a statement that can lose data, commented out unless destructive statements are allowed
+func (m *migrationScript) destructive(sql string) {
+	if m.allowDestructive {
+		m.statement(sql)
+		return
+	}
+	m.builder.WriteString("-- DESTRUCTIVE: " + sql + "\n")
+	m.skipped++
+}
+
+// sequenceDefault writes commented out a statement setting a default taken from DB1 that uses a
+// sequence, since the sequence is named as in DB1 and may not exist in DB2
+func (m *migrationScript) sequenceDefault(sql string) {
+	m.comment("The default uses a DB1 sequence: create or choose the DB2 sequence and set it manually")
+	m.builder.WriteString("-- " + sql + "\n")
+}
+
+// usesSequence reports whether a column default takes its values from a sequence
+func usesSequence(def string) bool {
+	return strings.Contains(def, "nextval(")
+}
+
+// GenerateMigrationScript builds an SQL migration that brings the structure of the DB2 table in line
+// with DB1 from a schema comparison. Statements are ordered so that each one can run: triggers,
+// constraints and indexes that differ are dropped first, then columns are added and altered, and
+// finally indexes, constraints and triggers are recreated from their DB1 definitions. Dropping
+// columns and changing column types can lose data, so those statements are commented out unless
+// allowDestructive is set. Column order differences cannot be migrated and are only noted.
+func GenerateMigrationScript(result *models.SchemaComparisonResult, allowDestructive bool) string {
+	target1, target2 := result.DB1Target(), result.DB2Target()
+	renamed := target1.Table != target2.Table
+	table := pq.QuoteIdentifier(target2.Schema) + "." + pq.QuoteIdentifier(target2.Table)
+	script := &migrationScript{allowDestructive: allowDestructive}
+
+	// Objects to drop from DB2 (only there, or changed) and to create from DB1 (only there, or changed)
+	var drops, creates []models.ObjectDifference
+	var stateChanges []models.ObjectDifference
+	constraintNames := make(map[string]bool)
+	for _, diff := range result.ObjectDifferences {
+		if diff.ObjectType == ObjectTypeConstraint {
+			constraintNames[diff.Name] = true
+		}
+	}
+	for _, diff := range result.ObjectDifferences {
+		// Indexes backing a primary key, unique or exclusion constraint follow their constraint
+		if diff.ObjectType == ObjectTypeIndex && constraintNames[diff.Name] {
+			continue
+		}
+
+		// A trigger that only changed its firing state is altered in place
+		if diff.ObjectType == ObjectTypeTrigger && diff.Change == ObjectChanged &&
+			objectDefinition(diff.DB1Definition, target1, renamed) == objectDefinition(diff.DB2Definition, target2, renamed) {
+			stateChanges = append(stateChanges, diff)
+			continue
+		}
+
+		if diff.Change != ObjectRemoved {
+			drops = append(drops, diff)
+		}
+		if diff.Change != ObjectAdded {
+			creates = append(creates, diff)
+		}
+	}
+
+	// Triggers first, then foreign keys before the keys they may depend on, then the remaining constraints and indexes
+	dropOrder := []func(models.ObjectDifference) bool{
+		func(d models.ObjectDifference) bool { return d.ObjectType == ObjectTypeTrigger },
+		func(d models.ObjectDifference) bool {
+			return d.ObjectType == ObjectTypeConstraint && d.DB2Kind == "FOREIGN KEY"
+		},
+		func(d models.ObjectDifference) bool {
+			return d.ObjectType == ObjectTypeConstraint && d.DB2Kind != "FOREIGN KEY"
+		},
+		func(d models.ObjectDifference) bool { return d.ObjectType == ObjectTypeIndex },
+	}
+	if len(drops) > 0 {
+		script.section("Drop triggers, constraints and indexes that differ from DB1")
+		for _, matches := range dropOrder {
+			for _, diff := range drops {
+				if !matches(diff) {
+					continue
+				}
+				name := db2ObjectName(diff, target1, target2)
+				switch diff.ObjectType {
+				case ObjectTypeTrigger:
+					script.statement(fmt.Sprintf("DROP TRIGGER %s ON %s;", pq.QuoteIdentifier(name), table))
+				case ObjectTypeConstraint:
+					script.statement(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, pq.QuoteIdentifier(name)))
+				case ObjectTypeIndex:
+					script.statement(fmt.Sprintf("DROP INDEX %s.%s;", pq.QuoteIdentifier(target2.Schema), pq.QuoteIdentifier(name)))
+				}
+			}
+		}
+	}
+
+	if len(result.OnlyInDB1) > 0 {
+		script.section("Add columns missing in DB2")
+		for _, col := range result.OnlyInDB1 {
+			column := pq.QuoteIdentifier(target2.Column(col.ColumnName))
+			sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, columnType(col))
+			sequence := usesSequence(col.Default)
+			if col.Default != "" && !sequence {
+				sql += " DEFAULT " + col.Default
+			}
+			if !col.IsNullable {
+				if col.Default == "" || sequence {
+					script.comment(fmt.Sprintf("%s has no default: this fails if the table already has rows", col.ColumnName))
+				}
+				sql += " NOT NULL"
+			}
+			script.statement(sql + ";")
+			if sequence {
+				script.sequenceDefault(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, col.Default))
+			}
+		}
+	}
+
+	if len(result.ColumnDifferences) > 0 {
+		script.section("Alter columns that differ from DB1")
+		for _, diff := range result.ColumnDifferences {
+			column := pq.QuoteIdentifier(target2.Column(diff.ColumnName))
+			switch diff.Attribute {
+			case SchemaAttributeDataType:
+				script.destructive(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;",
+					table, column, diff.DB1Value, column, diff.DB1Value))
+			case SchemaAttributeDefault:
+				if diff.DB1Value == "" {
+					script.statement(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column))
+				} else if usesSequence(diff.DB1Value) {
+					script.sequenceDefault(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, diff.DB1Value))
+				} else {
+					script.statement(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, diff.DB1Value))
+				}
+			case SchemaAttributeNullable:
+				if diff.DB1Value == "NULL" {
+					script.statement(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", table, column))
+				} else {
+					script.comment(fmt.Sprintf("%s: this fails while DB2 has NULL values in the column", diff.ColumnName))
+					script.statement(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", table, column))
+				}
+			}
+		}
+	}
+
+	if len(result.OnlyInDB2) > 0 {
+		script.section("Drop columns that do not exist in DB1")
+		for _, col := range result.OnlyInDB2 {
+			script.destructive(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, pq.QuoteIdentifier(col.ColumnName)))
+		}
+	}
+
+	// Indexes, then keys and checks, then the foreign keys that may depend on them, then triggers
+	createOrder := []func(models.ObjectDifference) bool{
+		func(d models.ObjectDifference) bool { return d.ObjectType == ObjectTypeIndex },
+		func(d models.ObjectDifference) bool {
+			return d.ObjectType == ObjectTypeConstraint && d.DB1Kind != "FOREIGN KEY"
+		},
+		func(d models.ObjectDifference) bool {
+			return d.ObjectType == ObjectTypeConstraint && d.DB1Kind == "FOREIGN KEY"
+		},
+		func(d models.ObjectDifference) bool { return d.ObjectType == ObjectTypeTrigger },
+	}
+	if len(creates) > 0 {
+		script.section("Create indexes, constraints and triggers as defined in DB1")
+		if len(result.ColumnMap) > 0 {
+			script.comment("Definitions use DB1 column names: review them against the DB2 column map")
+		}
+		for _, matches := range createOrder {
+			for _, diff := range creates {
+				if !matches(diff) {
+					continue
+				}
+				name := migratedObjectName(diff.Name, target1, target2)
+				definition := retargetDefinition(diff.DB1Definition, target1, target2)
+				switch diff.ObjectType {
+				case ObjectTypeIndex:
+					script.statement(renameCreatedObject(definition, "INDEX", name) + ";")
+				case ObjectTypeConstraint:
+					script.statement(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, pq.QuoteIdentifier(name), definition))
+				case ObjectTypeTrigger:
+					script.statement(renameCreatedObject(definition, "TRIGGER", name) + ";")
+					if action := triggerStates[diff.DB1Kind]; diff.DB1Kind != "ENABLED" && action != "" {
+						script.statement(fmt.Sprintf("ALTER TABLE %s %s %s;", table, action, pq.QuoteIdentifier(name)))
+					}
+				}
+			}
+		}
+	}
+
+	if len(stateChanges) > 0 {
+		script.section("Set the firing state of triggers as in DB1")
+		for _, diff := range stateChanges {
+			if action := triggerStates[diff.DB1Kind]; action != "" {
+				script.statement(fmt.Sprintf("ALTER TABLE %s %s %s;", table, action, pq.QuoteIdentifier(db2ObjectName(diff, target1, target2))))
+			}
+		}
+	}
+
+	if result.ColumnOrderDiffers {
+		script.section("Column order differs and cannot be changed in place (rebuild the table to match it)")
+		script.comment("DB1: " + strings.Join(result.ColumnOrderDB1, ", "))
+		script.comment("DB2: " + strings.Join(result.ColumnOrderDB2, ", "))
+	}
+
+	var output strings.Builder
+	output.WriteString("-- Generated Schema Migration Script\n")
+	output.WriteString(fmt.Sprintf("-- Brings %s.%s (DB2) in line with %s.%s (DB1)\n", target2.Schema, target2.Table, target1.Schema, target1.Table))
+	output.WriteString(fmt.Sprintf("-- Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	output.WriteString(fmt.Sprintf("-- Statements: %d, destructive statements commented out: %d\n", script.statements, script.skipped))
+	if script.skipped > 0 {
+		output.WriteString("-- Rerun with -allow-destructive to enable the commented out statements\n")
+	}
+	output.WriteString("-- WARNING: Review this script before execution!\n")
+	output.WriteString("\n")
+	output.WriteString("BEGIN;\n")
+	output.WriteString(script.builder.String())
+	output.WriteString("\nCOMMIT;\n")
+
+	return output.String()
+}
+
+// migratedObjectName returns the name of a DB1 object created on the DB2 table, following the table
+// rename for generated names such as orders_pkey
+func migratedObjectName(name string, target1, target2 models.TableTarget) string {
+	if target1.Table != target2.Table && strings.HasPrefix(name, target1.Table+"_") {
+		return target2.Table + strings.TrimPrefix(name, target1.Table)
+	}
+	return name
+}
+
+// db2ObjectName returns the name of a differing object in DB2: objects reported under their DB1
+// name were paired with the DB2 object named after the DB2 table
+func db2ObjectName(diff models.ObjectDifference, target1, target2 models.TableTarget) string {
+	if diff.Change == ObjectAdded {
+		return diff.Name
+	}
+	return migratedObjectName(diff.Name, target1, target2)
+}
+
+// retargetDefinition rewrites the references to the DB1 table in a definition into references to the DB2 table
+func retargetDefinition(definition string, target1, target2 models.TableTarget) string {
+	if target1.Schema == target2.Schema && target1.Table == target2.Table {
+		return definition
+	}
+
+	table2 := pq.QuoteIdentifier(target2.Schema) + "." + pq.QuoteIdentifier(target2.Table)
+	replacements := []struct{ pattern, replacement string }{
+		{regexp.QuoteMeta(pq.QuoteIdentifier(target1.Schema) + "." + pq.QuoteIdentifier(target1.Table)), table2},
+		{`\b` + regexp.QuoteMeta(target1.Schema+"."+target1.Table) + `\b`, table2},
+		// Unqualified references, as printed when the table is on the search path
+		{`(\bON (?:ONLY )?|\bREFERENCES )` + regexp.QuoteMeta(target1.Table) + `\b`, "${1}" + table2},
+	}
+	for _, r := range replacements {
+		definition = regexp.MustCompile(r.pattern).ReplaceAllString(definition, r.replacement)
+	}
+	return definition
+}
+
+// renameCreatedObject replaces the name of the index or trigger created by a CREATE statement
+func renameCreatedObject(definition, objectKind, name string) string {
+	pattern := regexp.MustCompile(`^(CREATE (?:UNIQUE |CONSTRAINT )?` + objectKind + ` )("[^"]*"|\S+)`)
+	match := pattern.FindStringSubmatchIndex(definition)
+	if match == nil {
+		return definition
+	}
+	return definition[:match[4]] + pq.QuoteIdentifier(name) + definition[match[5]:]
+}
@@ -0,0 +1,328 @@
+package comparator
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/models"
+)
+
+// Significant digits kept when comparing floating point columns
+const (
+	realDigits            = 6
+	doublePrecisionDigits = 15
+)
+
+// allColumnsOption selects every column in a per-column option list
+const allColumnsOption = "*"
+
+// columnRules normalizes raw driver values into canonical forms before they are compared or used
+// in match keys. Normalization is driven by the PostgreSQL data type of each column, as reported by
+// GetTableSchema, plus the per-column options of the match criteria. The rules also carry the
+// primary key and row key of the table.
+type columnRules struct {
+	types           map[string]string
+	caseInsensitive map[string]bool
+	trim            map[string]bool
+	tolerances      map[string]columnTolerance
+	json            map[string]jsonOptions
+	udtNames        map[string]string
+	unorderedArrays map[string]bool
+	primaryKey      map[string]bool
+	rowKey          []string
+	nullEquivalents map[string]map[string]bool
+}
+
+// columnTolerance is the maximum amount or duration by which two values of a column may differ
+type columnTolerance struct {
+	amount   *big.Rat
+	duration time.Duration
+}
+
+// temporal reports whether the tolerance is a duration
+func (t columnTolerance) temporal() bool {
+	return t.amount == nil
+}
+
+// parseTolerance parses a tolerance given as a number ("0.01") or a duration ("5s", "1h30m")
+func parseTolerance(value string) (columnTolerance, error) {
+	value = strings.TrimSpace(value)
+
+	if amount, ok := new(big.Rat).SetString(value); ok {
+		if amount.Sign() < 0 {
+			return columnTolerance{}, fmt.Errorf("tolerance %q must not be negative", value)
+		}
+		return columnTolerance{amount: amount}, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return columnTolerance{}, fmt.Errorf("tolerance %q is neither a number nor a duration", value)
+	}
+	if duration < 0 {
+		return columnTolerance{}, fmt.Errorf("tolerance %q must not be negative", value)
+	}
+	return columnTolerance{duration: duration}, nil
+}
+
+// isTemporalType reports whether a PostgreSQL data type holds points in time
+func isTemporalType(dataType string) bool {
+	return strings.HasPrefix(dataType, "timestamp") || strings.HasPrefix(dataType, "time ") || dataType == "date"
+}
+
+// newColumnRules builds the normalization rules of a table. A nil schema yields rules that only
+// apply the per-column options.
+func newColumnRules(schema *models.TableSchema, criteria *models.MatchCriteria) *columnRules {
+	rules := &columnRules{
+		types:           make(map[string]string),
+		caseInsensitive: make(map[string]bool),
+		trim:            make(map[string]bool),
+		tolerances:      make(map[string]columnTolerance),
+		json:            make(map[string]jsonOptions),
+		udtNames:        make(map[string]string),
+		unorderedArrays: make(map[string]bool),
+		primaryKey:      make(map[string]bool),
+		nullEquivalents: make(map[string]map[string]bool),
+	}
+
+	if schema != nil {
+		for _, col := range schema.PrimaryKey {
+			rules.primaryKey[col] = true
+		}
+		rules.rowKey = schema.RowKey()
+		for _, col := range schema.Columns {
+			rules.types[col.ColumnName] = col.DataType
+			rules.udtNames[col.ColumnName] = col.UDTName
+			if isJSONType(col.DataType) && criteria != nil {
+				rules.json[col.ColumnName] = jsonOptionsFor(col.ColumnName, criteria)
+			}
+		}
+	}
+
+	if criteria != nil {
+		for _, col := range criteria.CaseInsensitiveColumns {
+			rules.caseInsensitive[col] = true
+		}
+		for _, col := range criteria.TrimColumns {
+			rules.trim[col] = true
+		}
+		for _, col := range criteria.UnorderedArrayColumns {
+			rules.unorderedArrays[col] = true
+		}
+		for col, kinds := range criteria.NullEquivalents {
+			rules.nullEquivalents[col] = make(map[string]bool)
+			for _, kind := range kinds {
+				rules.nullEquivalents[col][kind] = true
+			}
+		}
+		// Invalid tolerances are rejected by validateCriteria
+		for col, value := range criteria.Tolerances {
+			if tolerance, err := parseTolerance(value); err == nil {
+				rules.tolerances[col] = tolerance
+			}
+		}
+	}
+
+	return rules
+}
+
+// isPrimaryKey reports whether a column is part of the primary key of the table
+func (r *columnRules) isPrimaryKey(col string) bool {
+	return r.primaryKey[col]
+}
+
+// hasOption reports whether a per-column option applies to a column
+func hasOption(option map[string]bool, col string) bool {
+	return option[col] || option[allColumnsOption]
+}
+
+// normalize returns the canonical form of a column value
+func (r *columnRules) normalize(col string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	dataType := r.types[col]
+
+	// Text-like types come back from lib/pq as []byte; bytea stays binary
+	if b, ok := value.([]byte); ok && dataType != "" && dataType != "bytea" {
+		value = string(b)
+	}
+
+	switch dataType {
+	case "numeric":
+		if s, ok := value.(string); ok {
+			value = canonicalNumeric(s)
+		}
+	case "real":
+		if f, ok := value.(float64); ok {
+			value = roundSignificant(f, realDigits)
+		}
+	case "double precision":
+		if f, ok := value.(float64); ok {
+			value = roundSignificant(f, doublePrecisionDigits)
+		}
+	case "timestamp with time zone", "time with time zone":
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC()
+		}
+	case "timestamp without time zone", "time without time zone", "date":
+		// Only the wall clock is meaningful; drop whatever location the driver attached
+		if t, ok := value.(time.Time); ok {
+			value = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+	case "character":
+		if s, ok := value.(string); ok {
+			value = strings.TrimRight(s, " ")
+		}
+	case "uuid":
+		if s, ok := value.(string); ok {
+			value = strings.ToLower(s)
+		}
+	case "ARRAY":
+		if elements, ok := r.decodeArray(col, value); ok {
+			return r.canonicalArray(col, elements)
+		}
+	case "json", "jsonb":
+		if s, ok := value.(string); ok {
+			if doc, err := parseOrderedJSON(s); err == nil {
+				return canonicalJSON(doc, nil, r.json[col])
+			}
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		if hasOption(r.trim, col) {
+			s = strings.TrimSpace(s)
+		}
+		if hasOption(r.caseInsensitive, col) {
+			s = strings.ToLower(s)
+		}
+		value = s
+	}
+
+	return value
+}
+
+// equal reports whether two values of a column are equal once normalized
+func (r *columnRules) equal(col string, val1, val2 interface{}) bool {
+	norm1 := r.normalize(col, val1)
+	norm2 := r.normalize(col, val2)
+
+	if t1, ok := norm1.(time.Time); ok {
+		if t2, ok := norm2.(time.Time); ok {
+			return t1.Equal(t2)
+		}
+	}
+
+	return reflect.DeepEqual(norm1, norm2)
+}
+
+// jsonDiff returns the path-level differences of two values of a JSON column, or nil when the
+// column is not JSON or a value cannot be parsed
+func (r *columnRules) jsonDiff(col string, val1, val2 interface{}) []models.JSONPathDifference {
+	if !isJSONType(r.types[col]) || val1 == nil || val2 == nil {
+		return nil
+	}
+
+	doc1, err1 := parseOrderedJSON(fmt.Sprintf("%s", convertBytesToString(val1)))
+	doc2, err2 := parseOrderedJSON(fmt.Sprintf("%s", convertBytesToString(val2)))
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	return diffJSON(doc1, doc2, nil, r.json[col])
+}
+
+// withinTolerance reports whether two differing values of a column are within its tolerance
+func (r *columnRules) withinTolerance(col string, val1, val2 interface{}) bool {
+	tolerance, exists := r.tolerances[col]
+	if !exists {
+		return false
+	}
+
+	norm1 := r.normalize(col, val1)
+	norm2 := r.normalize(col, val2)
+
+	if tolerance.temporal() {
+		t1, ok1 := norm1.(time.Time)
+		t2, ok2 := norm2.(time.Time)
+		if !ok1 || !ok2 {
+			return false
+		}
+		delta := t1.Sub(t2)
+		if delta < 0 {
+			delta = -delta
+		}
+		return delta <= tolerance.duration
+	}
+
+	n1, ok1 := ratValue(norm1)
+	n2, ok2 := ratValue(norm2)
+	if !ok1 || !ok2 {
+		return false
+	}
+	delta := new(big.Rat).Sub(n1, n2)
+	return delta.Abs(delta).Cmp(tolerance.amount) <= 0
+}
+
+// ratValue converts a normalized numeric value into an exact rational
+func ratValue(value interface{}) (*big.Rat, bool) {
+	switch v := value.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case float64:
+		rat := new(big.Rat)
+		if rat.SetFloat64(v) == nil {
+			return nil, false
+		}
+		return rat, true
+	case string:
+		return new(big.Rat).SetString(v)
+	}
+	return nil, false
+}
+
+// canonicalNumeric strips insignificant zeros from the text form of a numeric value,
+// so that 1.50, 1.5 and 01.500 compare equal
+func canonicalNumeric(s string) string {
+	s = strings.TrimSpace(s)
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+
+	// NaN and Infinity have no digits to normalize
+	if s == "" || (s[0] != '.' && (s[0] < '0' || s[0] > '9')) {
+		if negative {
+			return "-" + s
+		}
+		return s
+	}
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	s = strings.TrimLeft(s, "0")
+	if s == "" || strings.HasPrefix(s, ".") {
+		s = "0" + s
+	}
+
+	if negative && s != "0" {
+		return "-" + s
+	}
+	return s
+}
+
+// roundSignificant rounds a float to the given number of significant digits
+func roundSignificant(f float64, digits int) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', digits, 64), 64)
+	if err != nil {
+		return f
+	}
+	return rounded
+}
@@ -0,0 +1,108 @@
+package comparator
+
+import "fmt"
+
+// Values that can be declared equivalent to NULL
+const (
+	NullEquivalentEmpty = "empty" // '' in text columns
+	NullEquivalentZero  = "zero"  // 0 in numeric columns
+	NullEquivalentFalse = "false" // false in boolean columns
+)
+
+// nullEquivalentApplies reports whether a NULL equivalent can occur in a column of the given data type
+func nullEquivalentApplies(kind, dataType string) bool {
+	switch kind {
+	case NullEquivalentEmpty:
+		return dataType == "text" || dataType == "character varying" || dataType == "character"
+	case NullEquivalentZero:
+		switch dataType {
+		case "smallint", "integer", "bigint", "numeric", "real", "double precision":
+			return true
+		}
+	case NullEquivalentFalse:
+		return dataType == "boolean"
+	}
+	return false
+}
+
+// validateNullEquivalent checks the kind of a NULL equivalent and, for a named column, that it suits the column type
+func validateNullEquivalent(col, kind string, columns map[string]string) error {
+	if kind != NullEquivalentEmpty && kind != NullEquivalentZero && kind != NullEquivalentFalse {
+		return fmt.Errorf("unknown NULL equivalent %q for column %s (expected %s, %s or %s)",
+			kind, col, NullEquivalentEmpty, NullEquivalentZero, NullEquivalentFalse)
+	}
+	if col == allColumnsOption {
+		return nil
+	}
+
+	dataType, exists := columns[col]
+	if !exists {
+		return fmt.Errorf("NULL equivalent column %s does not exist", col)
+	}
+	if !nullEquivalentApplies(kind, dataType) {
+		return fmt.Errorf("NULL equivalent %q does not apply to column %s of type %s", kind, col, dataType)
+	}
+	return nil
+}
+
+// hasNullEquivalents reports whether a NULL equivalent applies to a column
+func (r *columnRules) hasNullEquivalents(col string) bool {
+	for _, kinds := range []map[string]bool{r.nullEquivalents[col], r.nullEquivalents[allColumnsOption]} {
+		for kind := range kinds {
+			if nullEquivalentApplies(kind, r.types[col]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNullEquivalent reports whether a normalized, non-NULL value of a column compares equal to NULL
+func (r *columnRules) isNullEquivalent(col string, normalized interface{}) bool {
+	for _, kinds := range []map[string]bool{r.nullEquivalents[col], r.nullEquivalents[allColumnsOption]} {
+		for kind := range kinds {
+			if !nullEquivalentApplies(kind, r.types[col]) {
+				continue
+			}
+
+			switch kind {
+			case NullEquivalentEmpty:
+				if normalized == "" {
+					return true
+				}
+			case NullEquivalentZero:
+				if n, ok := ratValue(normalized); ok && n.Sign() == 0 {
+					return true
+				}
+			case NullEquivalentFalse:
+				if normalized == false {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// nullEquivalent reports whether two values of a column differ only because one is NULL and
+// the other is one of its declared equivalents
+func (r *columnRules) nullEquivalent(col string, val1, val2 interface{}) bool {
+	if len(r.nullEquivalents) == 0 || (val1 == nil) == (val2 == nil) {
+		return false
+	}
+
+	if val1 == nil {
+		return r.isNullEquivalent(col, r.normalize(col, val2))
+	}
+	return r.isNullEquivalent(col, r.normalize(col, val1))
+}
+
+// keyValue returns the value of a column used in match keys: its normalized form, with NULL
+// equivalents folded into NULL so that such rows still pair up
+func (r *columnRules) keyValue(col string, value interface{}) interface{} {
+	normalized := r.normalize(col, value)
+	if normalized != nil && len(r.nullEquivalents) > 0 && r.isNullEquivalent(col, normalized) {
+		return nil
+	}
+	return normalized
+}
@@ -0,0 +1,147 @@
+package comparator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// Types of table objects compared by the object comparison
+const (
+	ObjectTypeConstraint = "constraint"
+	ObjectTypeIndex      = "index"
+	ObjectTypeTrigger    = "trigger"
+)
+
+// Changes reported for a table object, from DB1 to DB2
+const (
+	ObjectAdded   = "added"
+	ObjectRemoved = "removed"
+	ObjectChanged = "changed"
+)
+
+// tablePlaceholder replaces the name of the compared table in object names and definitions
+const tablePlaceholder = "<table>"
+
+// objectSource fetches one type of table object from a database
+type objectSource struct {
+	objectType string
+	fetch      func(conn *database.Connection, schema, tableName string) ([]models.TableObject, error)
+}
+
+// tableObjectSources lists the table objects compared, in report order
+var tableObjectSources = []objectSource{
+	{ObjectTypeConstraint, (*database.Connection).GetTableConstraints},
+	{ObjectTypeIndex, (*database.Connection).GetTableIndexes},
+	{ObjectTypeTrigger, (*database.Connection).GetTableTriggers},
+}
+
+// compareTableObjects compares the constraints, indexes and triggers of the compared table.
+// Objects are paired by name; when the DB2 table has another name, names and definitions are
+// compared with the table name factored out, so that orders_pkey pairs with orders_backup_pkey.
+func (c *Comparator) compareTableObjects(target1, target2 models.TableTarget) ([]models.ObjectDifference, error) {
+	differences := []models.ObjectDifference{}
+
+	for _, source := range tableObjectSources {
+		objects1, err := source.fetch(c.DB1, target1.Schema, target1.Table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %ss from DB1: %w", source.objectType, err)
+		}
+
+		objects2, err := source.fetch(c.DB2, target2.Schema, target2.Table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %ss from DB2: %w", source.objectType, err)
+		}
+
+		differences = append(differences, diffTableObjects(source.objectType, objects1, objects2, target1, target2)...)
+	}
+
+	return differences, nil
+}
+
+// diffTableObjects reports the objects of one type that were removed, added or changed from DB1 to DB2
+func diffTableObjects(objectType string, objects1, objects2 []models.TableObject, target1, target2 models.TableTarget) []models.ObjectDifference {
+	var differences []models.ObjectDifference
+
+	renamed := target1.Table != target2.Table
+	byName2 := make(map[string]models.TableObject)
+	for _, object := range objects2 {
+		byName2[objectName(object.Name, target2, renamed)] = object
+	}
+
+	paired := make(map[string]bool)
+	for _, object1 := range objects1 {
+		name := objectName(object1.Name, target1, renamed)
+		object2, exists := byName2[name]
+		if !exists {
+			differences = append(differences, models.ObjectDifference{
+				ObjectType:    objectType,
+				Name:          object1.Name,
+				Change:        ObjectRemoved,
+				DB1Kind:       object1.Kind,
+				DB1Definition: object1.Definition,
+			})
+			continue
+		}
+		paired[name] = true
+
+		if object1.Kind != object2.Kind ||
+			objectDefinition(object1.Definition, target1, renamed) != objectDefinition(object2.Definition, target2, renamed) {
+			differences = append(differences, models.ObjectDifference{
+				ObjectType:    objectType,
+				Name:          object1.Name,
+				Change:        ObjectChanged,
+				DB1Kind:       object1.Kind,
+				DB2Kind:       object2.Kind,
+				DB1Definition: object1.Definition,
+				DB2Definition: object2.Definition,
+			})
+		}
+	}
+
+	for _, object2 := range objects2 {
+		if paired[objectName(object2.Name, target2, renamed)] {
+			continue
+		}
+		differences = append(differences, models.ObjectDifference{
+			ObjectType:    objectType,
+			Name:          object2.Name,
+			Change:        ObjectAdded,
+			DB2Kind:       object2.Kind,
+			DB2Definition: object2.Definition,
+		})
+	}
+
+	return differences
+}
+
+// objectName returns the name an object is paired on, with a leading table name factored out
+// when the two tables are named differently
+func objectName(name string, target models.TableTarget, renamed bool) string {
+	if renamed && strings.HasPrefix(name, target.Table+"_") {
+		return tablePlaceholder + strings.TrimPrefix(name, target.Table)
+	}
+	return name
+}
+
+// objectDefinition returns a definition with the qualified name of its table factored out, and
+// also the table part of generated object names when the two tables are named differently
+func objectDefinition(definition string, target models.TableTarget, renamed bool) string {
+	patterns := []string{
+		regexp.QuoteMeta(pq.QuoteIdentifier(target.Schema) + "." + pq.QuoteIdentifier(target.Table)),
+		`\b` + regexp.QuoteMeta(target.Schema+"."+target.Table) + `\b`,
+	}
+	if renamed {
+		patterns = append(patterns, `\b`+regexp.QuoteMeta(target.Table)+`(_\w)`)
+	}
+
+	for _, pattern := range patterns {
+		definition = regexp.MustCompile(pattern).ReplaceAllString(definition, tablePlaceholder+"$1")
+	}
+	return definition
+}
@@ -0,0 +1,101 @@
+package comparator
+
+import (
+	"fmt"
+	"time"
+
+	"deepComparator/pkg/models"
+)
+
+// EstimatedCountTolerance is the relative difference of planner row estimates below which two
+// tables are not reported as diverging, since estimates drift between analyzes
+const EstimatedCountTolerance = 0.05
+
+// TableOverview lists the tables of both databases side by side with their row counts, on-disk size
+// and last analyze time, flagging tables whose row counts diverge or that exist in only one database.
+// Row counts are planner estimates unless exactCounts is set. Schemas limits the listing; empty
+// means all schemas.
+func (c *Comparator) TableOverview(schemas []string, exactCounts bool) (*models.TableOverviewResult, error) {
+	tables1, err := c.DB1.GetTableStats(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB1 table stats: %w", err)
+	}
+
+	tables2, err := c.DB2.GetTableStats(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB2 table stats: %w", err)
+	}
+
+	if exactCounts {
+		if err := c.ConcurrentWorker.ParallelExactRowCounts(tables1, tables2); err != nil {
+			return nil, fmt.Errorf("failed to count rows: %w", err)
+		}
+	}
+
+	result := &models.TableOverviewResult{
+		Timestamp:   time.Now(),
+		Schemas:     schemas,
+		ExactCounts: exactCounts,
+		Tables:      []models.TableOverview{},
+	}
+
+	byName2 := make(map[string]models.TableInfo)
+	for _, table := range tables2 {
+		byName2[table.Schema+"."+table.TableName] = table
+	}
+
+	paired := make(map[string]bool)
+	for _, table1 := range tables1 {
+		name := table1.Schema + "." + table1.TableName
+		overview := models.TableOverview{Schema: table1.Schema, TableName: table1.TableName, DB1: &table1.TableStats}
+
+		if table2, exists := byName2[name]; exists {
+			paired[name] = true
+			overview.DB2 = &table2.TableStats
+			overview.RowDelta = table2.RowCount - table1.RowCount
+			overview.Diverges = countsDiverge(table1.RowCount, table2.RowCount, exactCounts)
+		} else {
+			overview.Diverges = true
+		}
+		result.Tables = append(result.Tables, overview)
+	}
+
+	for _, table2 := range tables2 {
+		if paired[table2.Schema+"."+table2.TableName] {
+			continue
+		}
+		result.Tables = append(result.Tables, models.TableOverview{
+			Schema:    table2.Schema,
+			TableName: table2.TableName,
+			DB2:       &table2.TableStats,
+			Diverges:  true,
+		})
+	}
+
+	result.TotalTables = len(result.Tables)
+	for _, table := range result.Tables {
+		if table.Diverges {
+			result.DivergingTables++
+		}
+	}
+
+	return result, nil
+}
+
+// countsDiverge reports whether two row counts differ; estimates must differ by more than
+// EstimatedCountTolerance, and a table never analyzed in one database always diverges
+func countsDiverge(count1, count2 int64, exact bool) bool {
+	if exact || count1 < 0 || count2 < 0 {
+		return count1 != count2
+	}
+
+	delta := count1 - count2
+	if delta < 0 {
+		delta = -delta
+	}
+	largest := count1
+	if count2 > largest {
+		largest = count2
+	}
+	return largest > 0 && float64(delta)/float64(largest) > EstimatedCountTolerance
+}
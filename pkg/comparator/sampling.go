@@ -0,0 +1,198 @@
+package comparator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+
+	"github.com/lib/pq"
+)
+
+// Default settings of the sampling comparison
+const (
+	DefaultSampleMethod     = database.SampleMethodBernoulli
+	DefaultSampleConfidence = 0.95
+)
+
+// CompareTableSampled estimates how much a table diverges by comparing a random sample of
+// roughly percent % of the DB1 rows with their counterparts in DB2, looked up by match key, in
+// its normalized form for the key columns that are trimmed, lowercased or fold NULL equivalents.
+// Instead of exact lists the result carries a SamplingSummary with the estimated mismatch
+// rate and its confidence interval; the table-level foreign key comparison is skipped.
+func (c *Comparator) CompareTableSampled(schema, tableName string, criteria *models.MatchCriteria, method string, percent, confidence float64) (*models.ComparisonResult, error) {
+	if method == "" {
+		method = DefaultSampleMethod
+	}
+	if confidence <= 0 || confidence >= 1 {
+		confidence = DefaultSampleConfidence
+	}
+
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, _, err := c.loadTableSchemas(target1, target2)
+	if err != nil {
+		return nil, err
+	}
+
+	if criteria == nil {
+		criteria = c.createDefaultMatchCriteria(schema1)
+	}
+
+	if err := validateCriteria(schema1, criteria); err != nil {
+		return nil, err
+	}
+
+	keyCriteria, err := keyedCriteria(schema1, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("sampling comparison: %w", err)
+	}
+	keyColumns := keyCriteria.KeyColumns
+
+	summary := &models.SamplingSummary{
+		Method:          method,
+		Percent:         percent,
+		KeyColumns:      keyColumns,
+		ConfidenceLevel: confidence,
+	}
+
+	// Sample DB1; rows with a NULL key cannot be looked up in DB2
+	sampleProgress := progress.NewSimpleProgress(fmt.Sprintf("Sampling %g%% of %s.%s", percent, schema, tableName))
+	rules := newColumnRules(schema1, criteria)
+	lookupExprs1, err := rules.lookupKeyExprs(keyColumns, target1)
+	if err != nil {
+		return nil, fmt.Errorf("sampling comparison: %w", err)
+	}
+	lookupExprs2, err := rules.lookupKeyExprs(keyColumns, target2)
+	if err != nil {
+		return nil, fmt.Errorf("sampling comparison: %w", err)
+	}
+
+	sample, err := c.DB1.GetSampleRows(target1.Schema, target1.Table, target1.Where, method, percent, lookupExprs1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample DB1: %w", err)
+	}
+
+	var rows1 []models.TableRow
+	var keys [][]string
+	for _, sampled := range sample {
+		key := make([]string, len(sampled.Key))
+		complete := true
+		for i, value := range sampled.Key {
+			if !value.Valid {
+				complete = false
+				break
+			}
+			key[i] = value.String
+		}
+		if !complete {
+			summary.SkippedNullKeys++
+			continue
+		}
+		rows1 = append(rows1, sampled.Row)
+		keys = append(keys, key)
+	}
+	sampleProgress.Finish(fmt.Sprintf("Sampled %d rows", len(rows1)))
+
+	lookupProgress := progress.NewSimpleProgress("Looking up sampled rows in DB2")
+	rows2, err := c.DB2.GetRowsByKey(target2.Schema, target2.Table, target2.Where, lookupExprs2, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up sampled rows in DB2: %w", err)
+	}
+	rows2 = target2.CanonicalRows(rows2)
+	lookupProgress.Finish(fmt.Sprintf("Found %d rows", len(rows2)))
+
+	matches, onlyInDB1, _, _ := c.matchRows(rows1, rows2, keyCriteria, rules)
+	for _, match := range matches {
+		diff := c.compareRows(match.row1, match.row2, keyCriteria, rules)
+		if len(diff.ColumnDifferences) > 0 {
+			summary.RowsWithDifferences++
+		}
+	}
+
+	summary.SampledRows = len(rows1)
+	summary.MissingInDB2 = len(onlyInDB1)
+	summary.MismatchedRows = summary.MissingInDB2 + summary.RowsWithDifferences
+	summary.MismatchRate, summary.ConfidenceLow, summary.ConfidenceHigh =
+		wilsonInterval(summary.MismatchedRows, summary.SampledRows, confidence)
+
+	// Extrapolate to the whole table only when the planner estimate describes the sampled population
+	if criteria.WhereDB1 == "" {
+		estimate, err := c.DB1.GetEstimatedRowCount(schema, tableName)
+		if err != nil {
+			return nil, err
+		}
+		if estimate > 0 {
+			summary.EstimatedTableRows = estimate
+			summary.EstimatedMismatchedRows = int64(math.Round(summary.MismatchRate * float64(estimate)))
+		}
+	}
+
+	result := &models.ComparisonResult{
+		TableName:         tableName,
+		Schema:            schema,
+		Timestamp:         time.Now(),
+		TotalRowsDB1:      len(rows1),
+		TotalRowsDB2:      len(rows2),
+		MatchedRows:       len(matches),
+		UnmatchedRows:     len(onlyInDB1),
+		OnlyInDB1:         []models.TableRow{},
+		OnlyInDB2:         []models.TableRow{},
+		Differences:       []models.RowDifference{},
+		ForeignKeyResults: []models.ForeignKeyResult{},
+		WhereDB1:          criteria.WhereDB1,
+		WhereDB2:          criteria.WhereDB2,
+		Sampling:          summary,
+	}
+	recordDB2Target(result, target1, target2)
+
+	return result, nil
+}
+
+// wilsonInterval returns the observed proportion of mismatches and its Wilson score interval
+// at the given confidence level
+func wilsonInterval(mismatches, n int, confidence float64) (float64, float64, float64) {
+	if n == 0 {
+		return 0, 0, 1
+	}
+
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	p := float64(mismatches) / float64(n)
+	nf := float64(n)
+
+	denominator := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denominator
+
+	return p, math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// lookupKeyExprs returns the SQL expressions sampled rows are read and looked up by: the key
+// columns themselves, so that their indexes can be used, except for the columns whose match key
+// is trimmed, lowercased or folds NULL equivalents, which are compared in their normalized form
+// so that DB2 rows pairing with the sampled ones are found
+func (r *columnRules) lookupKeyExprs(keyColumns []string, target models.TableTarget) ([]string, error) {
+	exprs := columnRefs(keyColumns, target)
+	for i, col := range keyColumns {
+		if !hasOption(r.trim, col) && !hasOption(r.caseInsensitive, col) && !r.hasNullEquivalents(col) {
+			continue
+		}
+		expr, err := r.streamKeyExpr(col, exprs[i])
+		if err != nil {
+			return nil, err
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+// columnRefs returns the references of columns of a target table under the alias t
+func columnRefs(columns []string, target models.TableTarget) []string {
+	refs := make([]string, len(columns))
+	for i, col := range columns {
+		refs[i] = "t." + pq.QuoteIdentifier(target.Column(col))
+	}
+	return refs
+}
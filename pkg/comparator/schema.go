@@ -0,0 +1,164 @@
+package comparator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/models"
+)
+
+// Column attributes compared by the schema comparison
+const (
+	SchemaAttributeDataType = "data_type"
+	SchemaAttributeNullable = "nullable"
+	SchemaAttributeDefault  = "default"
+)
+
+// CompareTableSchema compares the structure of a table between the two databases: columns present
+// in only one of them, data type, nullability and default changes, the order of the columns, and
+// added, removed or changed constraints, indexes and triggers. The DB2 table and renamed columns
+// of the criteria are honored; criteria may be nil.
+func (c *Comparator) CompareTableSchema(schema, tableName string, criteria *models.MatchCriteria) (*models.SchemaComparisonResult, error) {
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, schema2, err := c.fetchTableSchemas(target1, target2)
+	if err != nil {
+		return nil, err
+	}
+
+	result := compareSchemas(schema1, schema2, target2)
+
+	objectDifferences, err := c.compareTableObjects(target1, target2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare table objects: %w", err)
+	}
+	result.ObjectDifferences = objectDifferences
+
+	if target2.Schema != target1.Schema {
+		result.DB2Schema = target2.Schema
+	}
+	if target2.Table != target1.Table {
+		result.DB2TableName = target2.Table
+	}
+	result.ColumnMap = target2.ColumnMap
+
+	return result, nil
+}
+
+// compareSchemas computes the structural differences of two table schemas, naming columns as in DB1
+func compareSchemas(schema1, schema2 *models.TableSchema, target2 models.TableTarget) *models.SchemaComparisonResult {
+	result := &models.SchemaComparisonResult{
+		TableName:         schema1.TableName,
+		Schema:            schema1.Schema,
+		Timestamp:         time.Now(),
+		OnlyInDB1:         []models.ColumnInfo{},
+		OnlyInDB2:         []models.ColumnInfo{},
+		ColumnDifferences: []models.SchemaColumnDifference{},
+		ObjectDifferences: []models.ObjectDifference{},
+	}
+
+	columns2 := make(map[string]models.ColumnInfo)
+	for _, col := range schema2.Columns {
+		columns2[col.ColumnName] = col
+	}
+
+	// Columns of DB2 that correspond to a DB1 column, keyed by their DB2 name
+	canonical := make(map[string]string)
+	var order1 []string
+
+	for _, col1 := range schema1.Columns {
+		name2 := target2.Column(col1.ColumnName)
+		col2, exists := columns2[name2]
+		if !exists {
+			result.OnlyInDB1 = append(result.OnlyInDB1, col1)
+			continue
+		}
+		canonical[name2] = col1.ColumnName
+		order1 = append(order1, col1.ColumnName)
+
+		if type1, type2 := columnType(col1), columnType(col2); type1 != type2 {
+			result.ColumnDifferences = append(result.ColumnDifferences, models.SchemaColumnDifference{
+				ColumnName: col1.ColumnName,
+				Attribute:  SchemaAttributeDataType,
+				DB1Value:   type1,
+				DB2Value:   type2,
+			})
+		}
+		if col1.IsNullable != col2.IsNullable {
+			result.ColumnDifferences = append(result.ColumnDifferences, models.SchemaColumnDifference{
+				ColumnName: col1.ColumnName,
+				Attribute:  SchemaAttributeNullable,
+				DB1Value:   nullability(col1),
+				DB2Value:   nullability(col2),
+			})
+		}
+		if col1.Default != col2.Default {
+			result.ColumnDifferences = append(result.ColumnDifferences, models.SchemaColumnDifference{
+				ColumnName: col1.ColumnName,
+				Attribute:  SchemaAttributeDefault,
+				DB1Value:   col1.Default,
+				DB2Value:   col2.Default,
+			})
+		}
+	}
+
+	var order2 []string
+	for _, col2 := range schema2.Columns {
+		name, exists := canonical[col2.ColumnName]
+		if !exists {
+			result.OnlyInDB2 = append(result.OnlyInDB2, col2)
+			continue
+		}
+		order2 = append(order2, name)
+	}
+
+	// Order is compared over the columns both tables share, so added or dropped columns do not count
+	if strings.Join(order1, ",") != strings.Join(order2, ",") {
+		result.ColumnOrderDiffers = true
+		result.ColumnOrderDB1 = order1
+		result.ColumnOrderDB2 = order2
+	}
+
+	return result
+}
+
+// columnType returns the full type of a column, falling back to its information_schema data type
+func columnType(col models.ColumnInfo) string {
+	if col.FormattedType != "" {
+		return col.FormattedType
+	}
+	return col.DataType
+}
+
+// nullability returns the nullability of a column as written in DDL
+func nullability(col models.ColumnInfo) string {
+	if col.IsNullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+// warnSchemaDifferences prints a warning before a data comparison when the two table structures disagree
+func warnSchemaDifferences(schema1, schema2 *models.TableSchema, target2 models.TableTarget) {
+	diff := compareSchemas(schema1, schema2, target2)
+	if !diff.HasDifferences() {
+		return
+	}
+
+	var parts []string
+	if len(diff.OnlyInDB1) > 0 {
+		parts = append(parts, fmt.Sprintf("%d columns only in DB1", len(diff.OnlyInDB1)))
+	}
+	if len(diff.OnlyInDB2) > 0 {
+		parts = append(parts, fmt.Sprintf("%d columns only in DB2", len(diff.OnlyInDB2)))
+	}
+	if len(diff.ColumnDifferences) > 0 {
+		parts = append(parts, fmt.Sprintf("%d column attribute differences", len(diff.ColumnDifferences)))
+	}
+	if diff.ColumnOrderDiffers {
+		parts = append(parts, "different column order")
+	}
+
+	fmt.Printf("Warning: %s.%s has a different structure in each database (%s); run -compare-schema for details\n",
+		schema1.Schema, schema1.TableName, strings.Join(parts, ", "))
+}
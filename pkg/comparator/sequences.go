@@ -0,0 +1,204 @@
+package comparator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+
+	"github.com/lib/pq"
+)
+
+// Issues reported for a sequence
+const (
+	SequenceIssueBehindColumn  = "behind_column"   // the next value is already used by the owning column
+	SequenceIssueNearExhausted = "near_exhaustion" // most of the sequence range is consumed
+)
+
+// SequenceExhaustionPercent is the share of its range a sequence may consume before it is reported
+const SequenceExhaustionPercent = 90.0
+
+// CheckSequences checks the health of the sequences of both databases: sequences whose next value
+// collides with the values already in their owning serial or identity column, sequences close to
+// running out of values, and sequences whose last value differs between the databases.
+// Schemas limits the check; empty means all schemas.
+func (c *Comparator) CheckSequences(schemas []string) (*models.SequenceHealthResult, error) {
+	sequences1, err := sequenceStates(c.DB1, schemas, "DB1")
+	if err != nil {
+		return nil, err
+	}
+
+	sequences2, err := sequenceStates(c.DB2, schemas, "DB2")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SequenceHealthResult{
+		Timestamp: time.Now(),
+		Schemas:   schemas,
+		Sequences: []models.SequenceComparison{},
+	}
+
+	byName2 := make(map[string]models.SequenceInfo)
+	for _, seq := range sequences2 {
+		byName2[seq.Schema+"."+seq.SequenceName] = seq
+	}
+
+	paired := make(map[string]bool)
+	for _, seq1 := range sequences1 {
+		name := seq1.Schema + "." + seq1.SequenceName
+		comparison := newSequenceComparison(seq1)
+		comparison.DB1 = &seq1.SequenceState
+
+		if seq2, exists := byName2[name]; exists {
+			paired[name] = true
+			comparison.DB2 = &seq2.SequenceState
+			comparison.Differs = seq1.NextValue != seq2.NextValue
+		}
+		result.Sequences = append(result.Sequences, comparison)
+	}
+
+	for _, seq2 := range sequences2 {
+		if paired[seq2.Schema+"."+seq2.SequenceName] {
+			continue
+		}
+		comparison := newSequenceComparison(seq2)
+		comparison.DB2 = &seq2.SequenceState
+		result.Sequences = append(result.Sequences, comparison)
+	}
+
+	result.TotalSequences = len(result.Sequences)
+	for _, seq := range result.Sequences {
+		if seq.AtRisk() {
+			result.AtRiskSequences++
+		}
+		if seq.Differs {
+			result.DifferingValues++
+		}
+	}
+
+	return result, nil
+}
+
+// sequenceStates lists the sequences of one database and evaluates their health against their owning columns
+func sequenceStates(conn *database.Connection, schemas []string, dbName string) ([]models.SequenceInfo, error) {
+	sequences, err := conn.GetSequences(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s sequences: %w", dbName, err)
+	}
+
+	var boundProgress *progress.ProgressBar
+	if len(sequences) > 0 {
+		boundProgress = progress.NewProgressBar(int64(len(sequences)), fmt.Sprintf("Checking %s sequences", dbName))
+	}
+
+	for i := range sequences {
+		seq := &sequences[i]
+		if seq.TableName != "" {
+			seq.ColumnBound, err = conn.GetColumnBound(seq.Schema, seq.TableName, seq.ColumnName, seq.IncrementBy < 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %s sequence %s.%s: %w", dbName, seq.Schema, seq.SequenceName, err)
+			}
+		}
+		evaluateSequence(&seq.SequenceState)
+
+		if boundProgress != nil {
+			boundProgress.Update(1)
+		}
+	}
+
+	if boundProgress != nil {
+		boundProgress.Finish()
+	}
+
+	return sequences, nil
+}
+
+// evaluateSequence computes how much of its range a sequence has used and the issues it has
+func evaluateSequence(state *models.SequenceState) {
+	span := float64(state.MaxValue) - float64(state.MinValue)
+	if span > 0 {
+		used := float64(state.NextValue) - float64(state.MinValue)
+		if state.IncrementBy < 0 {
+			used = float64(state.MaxValue) - float64(state.NextValue)
+		}
+		state.UsedPercent = used / span * 100
+	}
+
+	if state.ColumnBound != nil {
+		ascending := state.IncrementBy > 0
+		if (ascending && state.NextValue <= *state.ColumnBound) || (!ascending && state.NextValue >= *state.ColumnBound) {
+			state.Issues = append(state.Issues, SequenceIssueBehindColumn)
+		}
+	}
+
+	if state.UsedPercent >= SequenceExhaustionPercent {
+		state.Issues = append(state.Issues, SequenceIssueNearExhausted)
+	}
+}
+
+// newSequenceComparison returns a comparison entry described by a sequence of either database
+func newSequenceComparison(seq models.SequenceInfo) models.SequenceComparison {
+	return models.SequenceComparison{
+		Schema:       seq.Schema,
+		SequenceName: seq.SequenceName,
+		TableName:    seq.TableName,
+		ColumnName:   seq.ColumnName,
+		Identity:     seq.Identity,
+	}
+}
+
+// GenerateSetvalScript builds the setval statements that move the sequences of one database
+// ("db1" or "db2") that are behind their owning column past the values already in use.
+// Bounds are read again when the script runs, so rows inserted in between are covered.
+func GenerateSetvalScript(result *models.SequenceHealthResult, db string) string {
+	var script strings.Builder
+
+	script.WriteString("-- Generated Sequence setval Script\n")
+	script.WriteString(fmt.Sprintf("-- Database: %s\n", db))
+	script.WriteString(fmt.Sprintf("-- Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	script.WriteString("-- WARNING: Review this script before execution!\n")
+	script.WriteString("\n")
+
+	statements := 0
+	for _, seq := range result.Sequences {
+		state := seq.DB1
+		if db == "db2" {
+			state = seq.DB2
+		}
+		if state == nil || !hasIssue(state, SequenceIssueBehindColumn) {
+			continue
+		}
+
+		aggregate := "MAX"
+		if state.IncrementBy < 0 {
+			aggregate = "MIN"
+		}
+		sequence := pq.QuoteLiteral(pq.QuoteIdentifier(seq.Schema) + "." + pq.QuoteIdentifier(seq.SequenceName))
+
+		script.WriteString(fmt.Sprintf("-- %s.%s.%s: next value %d, column %s %d\n",
+			seq.Schema, seq.TableName, seq.ColumnName, state.NextValue, strings.ToLower(aggregate), *state.ColumnBound))
+		script.WriteString(fmt.Sprintf("SELECT setval(%s, (SELECT %s(%s) FROM %s.%s));\n", sequence, aggregate,
+			pq.QuoteIdentifier(seq.ColumnName), pq.QuoteIdentifier(seq.Schema), pq.QuoteIdentifier(seq.TableName)))
+		statements++
+	}
+
+	if statements == 0 {
+		script.WriteString("-- No sequence is behind its column\n")
+	}
+
+	return script.String()
+}
+
+// hasIssue reports whether a sequence state has the given issue
+func hasIssue(state *models.SequenceState, issue string) bool {
+	for _, i := range state.Issues {
+		if i == issue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,462 @@
+package comparator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+
+	"github.com/lib/pq"
+)
+
+// StreamSink receives the findings of a streaming comparison as soon as they are produced
+type StreamSink interface {
+	OnlyInDB1(row models.TableRow) error
+	OnlyInDB2(row models.TableRow) error
+	Difference(diff models.RowDifference) error
+	DuplicateKey(dup models.DuplicateKeyDifference) error
+}
+
+// CompareTableStreaming compares a table by reading both databases in match key order through
+// server-side cursors and merging them row by row. The key is normalized inside PostgreSQL the
+// same way rows are paired in memory, and key columns whose normalization cannot be reproduced
+// there are rejected. Findings are handed to the sink instead of being accumulated, so memory
+// stays bounded by the cursor batch size regardless of table size.
+// The table-level foreign key comparison needs every row in memory and is therefore skipped;
+// foreign key references of differing columns are still resolved.
+func (c *Comparator) CompareTableStreaming(schema, tableName string, criteria *models.MatchCriteria, batchSize int, sink StreamSink) (*models.ComparisonResult, error) {
+	if sink == nil {
+		return nil, fmt.Errorf("a stream sink is required for streaming comparison")
+	}
+
+	target1, target2 := criteria.Targets(schema, tableName)
+	schema1, schema2, err := c.loadTableSchemas(target1, target2)
+	if err != nil {
+		return nil, err
+	}
+
+	if criteria == nil {
+		criteria = c.createDefaultMatchCriteria(schema1)
+	}
+
+	if err := validateCriteria(schema1, criteria); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(schema1.Columns))
+	for i, col := range schema1.Columns {
+		columns[i] = col.ColumnName
+	}
+
+	rules := newColumnRules(schema1, criteria)
+	keyColumns := c.matchKeyColumns(columns, criteria, rules)
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("no match key columns left for %s.%s after exclusions", schema, tableName)
+	}
+
+	keyExprs1, err := rules.streamKeyExprs(keyColumns, target1)
+	if err != nil {
+		return nil, fmt.Errorf("streaming comparison: %w", err)
+	}
+	keyExprs2, err := rules.streamKeyExprs(keyColumns, target2)
+	if err != nil {
+		return nil, fmt.Errorf("streaming comparison: %w", err)
+	}
+
+	// Rows sharing a key are further ordered by the other compared columns, so that duplicates
+	// line up and can be paired one by one without holding the whole run in memory
+	tiebreakColumns := streamTiebreakColumns(c.comparedColumns(columns, criteria, rules), keyColumns, schema2, target2)
+	tiebreakExprs1 := rules.streamTiebreakExprs(tiebreakColumns, target1)
+	tiebreakExprs2 := rules.streamTiebreakExprs(tiebreakColumns, target2)
+
+	stream1, err := c.DB1.StreamTableData(target1.Schema, target1.Table, target1.Where, keyExprs1, tiebreakExprs1, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream on DB1: %w", err)
+	}
+	defer stream1.Close()
+
+	stream2, err := c.DB2.StreamTableData(target2.Schema, target2.Table, target2.Where, keyExprs2, tiebreakExprs2, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream on DB2: %w", err)
+	}
+	defer stream2.Close()
+
+	result := &models.ComparisonResult{
+		TableName:         tableName,
+		Schema:            schema,
+		Timestamp:         time.Now(),
+		OnlyInDB1:         []models.TableRow{},
+		OnlyInDB2:         []models.TableRow{},
+		Differences:       []models.RowDifference{},
+		ForeignKeyResults: []models.ForeignKeyResult{},
+		WhereDB1:          criteria.WhereDB1,
+		WhereDB2:          criteria.WhereDB2,
+		Streaming:         &models.StreamingSummary{},
+	}
+	recordDB2Target(result, target1, target2)
+
+	var mergeProgress *progress.ProgressBar
+	if stream1.TotalRows > 100 {
+		mergeProgress = progress.NewProgressBar(stream1.TotalRows, "Streaming comparison")
+	}
+
+	head1, err := newStreamHead(stream1, target1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from DB1: %w", err)
+	}
+	head2, err := newStreamHead(stream2, target2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from DB2: %w", err)
+	}
+
+	merge := &streamMerge{c: c, criteria: criteria, rules: rules, schema: schema1, sink: sink, result: result}
+	lastProgress := 0
+	for head1.row != nil || head2.row != nil {
+		var cmp int
+		switch {
+		case head1.row == nil:
+			cmp = 1
+		case head2.row == nil:
+			cmp = -1
+		default:
+			cmp = compareStreamKeys(head1.row.Key, head2.row.Key)
+		}
+
+		keyRow := head1.row
+		if cmp > 0 {
+			keyRow = head2.row
+		}
+		if err := merge.mergeKey(keyRow, head1, head2, cmp <= 0, cmp >= 0); err != nil {
+			return nil, err
+		}
+
+		if mergeProgress != nil && result.TotalRowsDB1-lastProgress >= 1000 {
+			lastProgress = result.TotalRowsDB1
+			mergeProgress.SetProgress(int64(lastProgress))
+		}
+	}
+
+	if mergeProgress != nil {
+		mergeProgress.FinishWithMessage(fmt.Sprintf("Merged %d/%d rows, %d differences",
+			result.TotalRowsDB1, result.TotalRowsDB2, result.Streaming.Differences))
+	}
+
+	result.UnmatchedRows = result.Streaming.OnlyInDB1 + result.Streaming.OnlyInDB2
+
+	return result, nil
+}
+
+// streamDuplicateSampleSize is the number of rows of each database kept in the duplicate key
+// differences of a streaming comparison
+const streamDuplicateSampleSize = 10
+
+// streamHead is the next unread row of a stream, with its columns renamed to the DB1 names
+type streamHead struct {
+	stream *database.RowStream
+	target models.TableTarget
+	row    *database.StreamedRow // nil once the stream is exhausted
+}
+
+// newStreamHead reads the first row of a stream on the given table
+func newStreamHead(stream *database.RowStream, target models.TableTarget) (*streamHead, error) {
+	head := &streamHead{stream: stream, target: target}
+	return head, head.advance()
+}
+
+// advance reads the next row of the stream
+func (h *streamHead) advance() error {
+	row, err := nextStreamedRow(h.stream)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		row.Row = h.target.CanonicalRow(row.Row)
+	}
+	h.row = row
+	return nil
+}
+
+// hasKey reports whether the next row of the stream has the given sort key
+func (h *streamHead) hasKey(key []sql.NullString) bool {
+	return h.row != nil && compareStreamKeys(key, h.row.Key) == 0
+}
+
+// streamMerge hands the findings of a streaming comparison to its sink and counts them
+type streamMerge struct {
+	c        *Comparator
+	criteria *models.MatchCriteria
+	rules    *columnRules
+	schema   *models.TableSchema
+	sink     StreamSink
+	result   *models.ComparisonResult
+}
+
+// mergeKey consumes the rows sharing the sort key of keyRow from the streams taking part, pairing
+// them one by one in tiebreak order. Rows without a counterpart are reported as unmatched and,
+// as in pairKeyGroup, a duplicate difference is reported when the key occurs more than once on
+// either side with different counts; it keeps only a sample of the rows.
+func (m *streamMerge) mergeKey(keyRow *database.StreamedRow, head1, head2 *streamHead, inDB1, inDB2 bool) error {
+	key := keyRow.Key
+	dup := models.DuplicateKeyDifference{
+		Key:     m.c.getRowKey(keyRow.Row, m.criteria, m.rules),
+		DB1Rows: []models.TableRow{},
+		DB2Rows: []models.TableRow{},
+	}
+
+	for {
+		has1 := inDB1 && head1.hasKey(key)
+		has2 := inDB2 && head2.hasKey(key)
+		if !has1 && !has2 {
+			break
+		}
+
+		if has1 {
+			dup.CountDB1++
+			if len(dup.DB1Rows) < streamDuplicateSampleSize {
+				dup.DB1Rows = append(dup.DB1Rows, head1.row.Row)
+			}
+		}
+		if has2 {
+			dup.CountDB2++
+			if len(dup.DB2Rows) < streamDuplicateSampleSize {
+				dup.DB2Rows = append(dup.DB2Rows, head2.row.Row)
+			}
+		}
+
+		var err error
+		switch {
+		case has1 && has2:
+			err = m.pair(head1.row.Row, head2.row.Row)
+		case has1:
+			err = m.onlyInDB1(head1.row.Row)
+		default:
+			err = m.onlyInDB2(head2.row.Row)
+		}
+		if err != nil {
+			return err
+		}
+
+		if has1 {
+			if err := head1.advance(); err != nil {
+				return fmt.Errorf("failed to read from DB1: %w", err)
+			}
+		}
+		if has2 {
+			if err := head2.advance(); err != nil {
+				return fmt.Errorf("failed to read from DB2: %w", err)
+			}
+		}
+	}
+
+	m.result.TotalRowsDB1 += dup.CountDB1
+	m.result.TotalRowsDB2 += dup.CountDB2
+
+	if dup.CountDB1 != dup.CountDB2 && (dup.CountDB1 > 1 || dup.CountDB2 > 1) {
+		if err := m.sink.DuplicateKey(m.c.UUIDDecoder.ProcessDuplicateKey(dup)); err != nil {
+			return fmt.Errorf("failed to emit duplicate key: %w", err)
+		}
+		m.result.Streaming.DuplicateKeys++
+	}
+	return nil
+}
+
+// pair compares two rows sharing a key
+func (m *streamMerge) pair(row1, row2 models.TableRow) error {
+	m.result.MatchedRows++
+
+	diff := m.c.compareRowsWithFK(row1, row2, m.criteria, m.rules, m.schema)
+	if len(diff.ColumnDifferences) > 0 {
+		diff.RowIdentifier = m.c.getRowIdentifier(row1, m.criteria, m.rules)
+		if err := m.sink.Difference(m.c.UUIDDecoder.ProcessRowDifference(*diff)); err != nil {
+			return fmt.Errorf("failed to emit difference: %w", err)
+		}
+		m.result.Streaming.Differences++
+	} else if len(diff.ToleratedDifferences) > 0 {
+		m.result.Streaming.WithinTolerance++
+	}
+	if len(diff.ColumnDifferences) == 0 && len(diff.NullEquivalentColumns) > 0 {
+		m.result.Streaming.NullEquivalent++
+	}
+	return nil
+}
+
+// onlyInDB1 reports a row without counterpart in DB2
+func (m *streamMerge) onlyInDB1(row models.TableRow) error {
+	if err := m.sink.OnlyInDB1(m.c.UUIDDecoder.ProcessTableRow(row)); err != nil {
+		return fmt.Errorf("failed to emit row only in DB1: %w", err)
+	}
+	m.result.Streaming.OnlyInDB1++
+	return nil
+}
+
+// onlyInDB2 reports a row without counterpart in DB1
+func (m *streamMerge) onlyInDB2(row models.TableRow) error {
+	if err := m.sink.OnlyInDB2(m.c.UUIDDecoder.ProcessTableRow(row)); err != nil {
+		return fmt.Errorf("failed to emit row only in DB2: %w", err)
+	}
+	m.result.Streaming.OnlyInDB2++
+	return nil
+}
+
+// nextStreamedRow returns the next row of a stream, or nil once the stream is exhausted
+func nextStreamedRow(stream *database.RowStream) (*database.StreamedRow, error) {
+	row, err := stream.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	return row, err
+}
+
+// compareStreamKeys orders two sort keys the same way the cursors do: byte-wise, NULLs last
+func compareStreamKeys(key1, key2 []sql.NullString) int {
+	for i := range key1 {
+		a, b := key1[i], key2[i]
+		switch {
+		case !a.Valid && !b.Valid:
+			continue
+		case !a.Valid:
+			return 1
+		case !b.Valid:
+			return -1
+		}
+
+		if cmp := strings.Compare(a.String, b.String); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// sqlSpaceCharacters are the characters removed by strings.TrimSpace, as a PostgreSQL text expression
+const sqlSpaceCharacters = `E' \t\n\x0B\f\r' || chr(133) || chr(160)`
+
+// streamTiebreakColumns returns the compared columns outside the key that exist in both tables
+func streamTiebreakColumns(compared, keyColumns []string, schema2 *models.TableSchema, target2 models.TableTarget) []string {
+	keyMap := make(map[string]bool, len(keyColumns))
+	for _, col := range keyColumns {
+		keyMap[col] = true
+	}
+	columns2 := make(map[string]bool, len(schema2.Columns))
+	for _, col := range schema2.Columns {
+		columns2[col.ColumnName] = true
+	}
+
+	var tiebreak []string
+	for _, col := range compared {
+		if !keyMap[col] && columns2[target2.Column(col)] {
+			tiebreak = append(tiebreak, col)
+		}
+	}
+	return tiebreak
+}
+
+// streamTiebreakExprs returns the SQL expressions ordering the rows of a key by the tiebreak
+// columns: their normalized text when it can be computed in SQL, else their plain text, which
+// only makes differing duplicates pair less closely
+func (r *columnRules) streamTiebreakExprs(columns []string, target models.TableTarget) []string {
+	exprs := make([]string, len(columns))
+	for i, col := range columns {
+		ref := "t." + pq.QuoteIdentifier(target.Column(col))
+		expr, err := r.streamKeyExpr(col, ref)
+		if err != nil {
+			expr = "(" + ref + ")::text"
+		}
+		exprs[i] = expr
+	}
+	return exprs
+}
+
+// streamKeyExprs returns the SQL sort key expressions of the key columns in the table of a target
+func (r *columnRules) streamKeyExprs(keyColumns []string, target models.TableTarget) ([]string, error) {
+	exprs := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		expr, err := r.streamKeyExpr(col, "t."+pq.QuoteIdentifier(target.Column(col)))
+		if err != nil {
+			return nil, err
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+// streamKeyExpr returns the text form of a key column as keyValue sees it, computed in SQL from
+// the column reference: numbers without insignificant zeros, floats rounded to their significant
+// digits, instants in UTC, JSON without formatting, the trim and case-insensitive options applied
+// and NULL equivalents folded into NULL. Two rows share a stream key exactly when they share a
+// match key.
+func (r *columnRules) streamKeyExpr(col, ref string) (string, error) {
+	dataType := r.types[col]
+	expr := "(" + ref + ")::text"
+
+	switch dataType {
+	case "numeric":
+		expr = sqlCanonicalNumeric(expr)
+	case "real", "double precision":
+		// The cast to numeric keeps 6 or 15 significant digits, as roundSignificant does
+		expr = fmt.Sprintf("CASE WHEN %s IN ('NaN', 'Infinity', '-Infinity') THEN %s ELSE %s END",
+			ref, expr, sqlCanonicalNumeric("("+ref+")::numeric::text"))
+	case "timestamp with time zone", "time with time zone":
+		expr = fmt.Sprintf("((%s) AT TIME ZONE 'UTC')::text", ref)
+	case "json", "jsonb":
+		options := r.json[col]
+		if options.unorderedArrays || len(options.ignorePaths) > 0 {
+			return "", fmt.Errorf("JSON column %s cannot be a stream key when its array order or paths are ignored; choose other columns with -key", col)
+		}
+		// jsonb is stored without formatting; json only loses it when its key order is ignored too
+		if dataType == "json" {
+			if !options.ignoreKeyOrder {
+				return "", fmt.Errorf("json column %s cannot be a stream key unless its key order is ignored; choose other columns with -key", col)
+			}
+			expr = "(" + ref + ")::jsonb::text"
+		}
+		return expr, nil
+	case "ARRAY":
+		switch {
+		case hasOption(r.unorderedArrays, col), hasOption(r.trim, col), hasOption(r.caseInsensitive, col):
+			return "", fmt.Errorf("array column %s cannot be a stream key when its elements are normalized; choose other columns with -key", col)
+		}
+		switch r.udtNames[col] {
+		case "_numeric", "_float4", "_float8", "_bpchar":
+			return "", fmt.Errorf("array column %s of type %s cannot be a stream key; choose other columns with -key", col, r.udtNames[col])
+		}
+		return expr, nil
+	}
+
+	if hasOption(r.trim, col) {
+		expr = fmt.Sprintf("btrim(%s, %s)", expr, sqlSpaceCharacters)
+	}
+	if hasOption(r.caseInsensitive, col) {
+		expr = fmt.Sprintf("lower(%s)", expr)
+	}
+
+	// Fold the canonical text of each NULL equivalent into NULL
+	for _, kinds := range []map[string]bool{r.nullEquivalents[col], r.nullEquivalents[allColumnsOption]} {
+		for kind := range kinds {
+			if !nullEquivalentApplies(kind, dataType) {
+				continue
+			}
+			switch kind {
+			case NullEquivalentEmpty:
+				expr = fmt.Sprintf("NULLIF(%s, '')", expr)
+			case NullEquivalentZero:
+				expr = fmt.Sprintf("NULLIF(%s, '0')", expr)
+			case NullEquivalentFalse:
+				expr = fmt.Sprintf("NULLIF(%s, 'false')", expr)
+			}
+		}
+	}
+
+	return expr, nil
+}
+
+// sqlCanonicalNumeric strips the insignificant zeros from the text of a numeric expression,
+// as canonicalNumeric does
+func sqlCanonicalNumeric(expr string) string {
+	return fmt.Sprintf("CASE WHEN strpos(%[1]s, '.') > 0 THEN rtrim(rtrim(%[1]s, '0'), '.') ELSE %[1]s END", expr)
+}
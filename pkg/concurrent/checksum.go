@@ -0,0 +1,97 @@
+package concurrent
+
+import (
+	"fmt"
+	"sync"
+
+	"deepComparator/pkg/models"
+)
+
+// ParallelRangeChecksums computes the checksum of every key range on both databases concurrently.
+// Key and hash columns are given by their DB1 names.
+func (cc *ConcurrentComparator) ParallelRangeChecksums(target1, target2 models.TableTarget, keyColumns, hashColumns []string, ranges []models.KeyRange) ([]models.RangeChecksumPair, error) {
+	pairs := make([]models.RangeChecksumPair, len(ranges))
+	for i, r := range ranges {
+		pairs[i].Range = r
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	keyColumns2 := target2.ColumnList(keyColumns)
+	hashColumns2 := target2.ColumnList(hashColumns)
+
+	semaphore := make(chan struct{}, cc.maxWorkers)
+
+	for i := range ranges {
+		wg.Add(2)
+
+		go func(idx int) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
+			defer func() { <-semaphore }() // Release semaphore
+
+			checksum, err := cc.DB1.GetRangeChecksum(target1.Schema, target1.Table, target1.Where, keyColumns, hashColumns, ranges[idx])
+			mu.Lock()
+			pairs[idx].DB1 = checksum
+			if err != nil {
+				errs = append(errs, fmt.Errorf("DB1 checksum error: %w", err))
+			}
+			mu.Unlock()
+		}(i)
+
+		go func(idx int) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
+			defer func() { <-semaphore }() // Release semaphore
+
+			checksum, err := cc.DB2.GetRangeChecksum(target2.Schema, target2.Table, target2.Where, keyColumns2, hashColumns2, ranges[idx])
+			mu.Lock()
+			pairs[idx].DB2 = checksum
+			if err != nil {
+				errs = append(errs, fmt.Errorf("DB2 checksum error: %w", err))
+			}
+			mu.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("checksum errors: %v", errs)
+	}
+
+	return pairs, nil
+}
+
+// ParallelRangeFetch fetches the rows of a key range from both databases concurrently,
+// returning DB2 rows with their columns renamed to the DB1 names
+func (cc *ConcurrentComparator) ParallelRangeFetch(target1, target2 models.TableTarget, keyColumns []string, r models.KeyRange) ([]models.TableRow, []models.TableRow, error) {
+	var rows1, rows2 []models.TableRow
+	var err1, err2 error
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		rows1, err1 = cc.DB1.GetRangeRows(target1.Schema, target1.Table, target1.Where, keyColumns, r)
+	}()
+
+	go func() {
+		defer wg.Done()
+		rows2, err2 = cc.DB2.GetRangeRows(target2.Schema, target2.Table, target2.Where, target2.ColumnList(keyColumns), r)
+	}()
+
+	wg.Wait()
+
+	if err1 != nil {
+		return nil, nil, fmt.Errorf("failed to fetch range from DB1: %w", err1)
+	}
+	if err2 != nil {
+		return nil, nil, fmt.Errorf("failed to fetch range from DB2: %w", err2)
+	}
+
+	return rows1, target2.CanonicalRows(rows2), nil
+}
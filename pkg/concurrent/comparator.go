@@ -31,8 +31,9 @@ func NewConcurrentComparator(db1, db2 *database.Connection, maxWorkers int) *Con
 	}
 }
 
-// ParallelDataFetch fetches table data and schema concurrently
-func (cc *ConcurrentComparator) ParallelDataFetch(schema, tableName string) (*models.TableData, *models.TableData, *models.TableSchema, error) {
+// ParallelDataFetch fetches table data and the DB1 schema concurrently, applying each side's row
+// filter. DB2 rows are returned with their columns renamed to the DB1 names.
+func (cc *ConcurrentComparator) ParallelDataFetch(target1, target2 models.TableTarget) (*models.TableData, *models.TableData, *models.TableSchema, error) {
 	type fetchResult struct {
 		data1  *models.TableData
 		data2  *models.TableData
@@ -58,7 +59,7 @@ func (cc *ConcurrentComparator) ParallelDataFetch(schema, tableName string) (*mo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			d1, err := cc.DB1.GetTableData(schema, tableName)
+			d1, err := cc.DB1.GetTableData(target1.Schema, target1.Table, target1.Where)
 			mu.Lock()
 			data1 = d1
 			if err != nil {
@@ -71,9 +72,12 @@ func (cc *ConcurrentComparator) ParallelDataFetch(schema, tableName string) (*mo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			d2, err := cc.DB2.GetTableData(schema, tableName)
+			d2, err := cc.DB2.GetTableData(target2.Schema, target2.Table, target2.Where)
 			mu.Lock()
 			data2 = d2
+			if d2 != nil {
+				d2.Rows = target2.CanonicalRows(d2.Rows)
+			}
 			if err != nil {
 				errs = append(errs, fmt.Errorf("DB2 data fetch error: %w", err))
 			}
@@ -84,7 +88,7 @@ func (cc *ConcurrentComparator) ParallelDataFetch(schema, tableName string) (*mo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			s, err := cc.DB1.GetTableSchema(schema, tableName)
+			s, err := cc.DB1.GetTableSchema(target1.Schema, target1.Table)
 			mu.Lock()
 			tableSchema = s
 			if err != nil {
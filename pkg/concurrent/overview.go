@@ -0,0 +1,59 @@
+package concurrent
+
+import (
+	"fmt"
+	"sync"
+
+	"deepComparator/pkg/database"
+	"deepComparator/pkg/models"
+	"deepComparator/pkg/progress"
+)
+
+// ParallelExactRowCounts replaces the estimated row counts of the tables of both databases with exact
+// counts, counting up to maxWorkers tables at a time
+func (cc *ConcurrentComparator) ParallelExactRowCounts(tables1, tables2 []models.TableInfo) error {
+	total := len(tables1) + len(tables2)
+	if total == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	countProgress := progress.NewProgressBar(int64(total), "Counting table rows")
+	semaphore := make(chan struct{}, cc.maxWorkers)
+
+	count := func(conn *database.Connection, dbName string, table *models.TableInfo) {
+		defer wg.Done()
+		semaphore <- struct{}{}        // Acquire semaphore
+		defer func() { <-semaphore }() // Release semaphore
+
+		rows, err := conn.GetExactRowCount(table.Schema, table.TableName)
+		mu.Lock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s count error: %w", dbName, err))
+		} else {
+			table.RowCount = rows
+		}
+		mu.Unlock()
+		countProgress.Update(1)
+	}
+
+	for i := range tables1 {
+		wg.Add(1)
+		go count(cc.DB1, "DB1", &tables1[i])
+	}
+	for i := range tables2 {
+		wg.Add(1)
+		go count(cc.DB2, "DB2", &tables2[i])
+	}
+
+	wg.Wait()
+	countProgress.Finish()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("count errors: %v", errs)
+	}
+	return nil
+}
@@ -12,11 +12,12 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Database1    models.DatabaseConfig `json:"database1"`
-	Database2    models.DatabaseConfig `json:"database2"`
-	OutputFormat string                `json:"output_format"`
-	OutputFile   string                `json:"output_file"`
-	LogLevel     string                `json:"log_level"`
+	Database1        models.DatabaseConfig `json:"database1"`
+	Database2        models.DatabaseConfig `json:"database2"`
+	OutputFormat     string                `json:"output_format"`
+	OutputFile       string                `json:"output_file"`
+	LogLevel         string                `json:"log_level"`
+	SingleConnection bool                  `json:"single_connection"`
 }
 
 // LoadConfig loads configuration from environment variables
@@ -65,10 +66,16 @@ func LoadConfig(envFile string) (*Config, error) {
 	config.OutputFile = getEnvOrDefault("OUTPUT_FILE", "comparison_result.json")
 	config.LogLevel = getEnvOrDefault("LOG_LEVEL", "info")
 
+	// Single-connection mode compares two tables of Database1
+	config.SingleConnection, err = strconv.ParseBool(getEnvOrDefault("SINGLE_CONNECTION", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SINGLE_CONNECTION: %w", err)
+	}
+
 	return config, nil
 }
 
-// Validate validates the configuration
+// Validate validates the configuration. Database2 is not required in single-connection mode.
 func (c *Config) Validate() error {
 	if c.Database1.Database == "" {
 		return fmt.Errorf("DB1_DATABASE is required")
@@ -76,6 +83,9 @@ func (c *Config) Validate() error {
 	if c.Database1.Username == "" {
 		return fmt.Errorf("DB1_USERNAME is required")
 	}
+	if c.SingleConnection {
+		return nil
+	}
 	if c.Database2.Database == "" {
 		return fmt.Errorf("DB2_DATABASE is required")
 	}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// qualifiedTable returns the quoted schema-qualified name of a table
+func qualifiedTable(schema, tableName string) string {
+	return fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(tableName))
+}
+
+// quotedColumns returns the quoted column references of alias t
+func quotedColumns(columns []string) []string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = "t." + pq.QuoteIdentifier(col)
+	}
+	return quoted
+}
+
+// keyRangeCondition builds the WHERE condition selecting the rows of a key range that satisfy
+// the optional where predicate. Bounds are compared with a row comparison on the native column types so that an
+// index on the key columns can be used; bound values are sent as text and cast by the server.
+func keyRangeCondition(keyColumns []string, r models.KeyRange, where string, firstArg int) (string, []interface{}) {
+	keys := quotedColumns(keyColumns)
+
+	var conds []string
+	if strings.TrimSpace(where) != "" {
+		conds = append(conds, "("+where+")")
+	}
+
+	if r.NullKeys {
+		nullConds := make([]string, len(keys))
+		for i, key := range keys {
+			nullConds[i] = key + " IS NULL"
+		}
+		conds = append(conds, "("+strings.Join(nullConds, " OR ")+")")
+		return strings.Join(conds, " AND "), nil
+	}
+
+	for _, key := range keys {
+		conds = append(conds, key+" IS NOT NULL")
+	}
+
+	var args []interface{}
+	bound := func(op string, values []string) {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = fmt.Sprintf("$%d", firstArg+len(args))
+			args = append(args, value)
+		}
+		conds = append(conds, fmt.Sprintf("ROW(%s) %s ROW(%s)",
+			strings.Join(keys, ", "), op, strings.Join(placeholders, ", ")))
+	}
+
+	if r.Lower != nil {
+		bound(">=", r.Lower)
+	}
+	if r.Upper != nil {
+		bound("<", r.Upper)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// beginRenderingTx begins a read-only transaction whose session settings render values as text
+// identically on every server, regardless of their defaults, so that text produced on one server
+// reads back as the same value on the other
+func (c *Connection) beginRenderingTx() (*sql.Tx, error) {
+	tx, err := c.DB.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+
+	for _, setting := range []string{"SET LOCAL TimeZone = 'UTC'", "SET LOCAL DateStyle = 'ISO, YMD'", "SET LOCAL IntervalStyle = 'postgres'"} {
+		if _, err := tx.Exec(setting); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to prepare session settings: %w", err)
+		}
+	}
+
+	return tx, nil
+}
+
+// GetRangeChecksum computes the row count and an order-independent aggregate hash of the
+// given columns for the rows of a key range. The hash is the sum of the first 64 bits of the
+// md5 of each row, so it can be computed without sorting and treats rows as a multiset.
+func (c *Connection) GetRangeChecksum(schema, tableName, where string, keyColumns, hashColumns []string, r models.KeyRange) (*models.RangeChecksum, error) {
+	cond, args := keyRangeCondition(keyColumns, r, where, 1)
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(('x' || substr(md5(ROW(%s)::text), 1, 16))::bit(64)::bigint::numeric), 0)::text
+		FROM %s t
+		WHERE %s`,
+		strings.Join(quotedColumns(hashColumns), ", "), qualifiedTable(schema, tableName), cond)
+
+	tx, err := c.beginRenderingTx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	checksum := &models.RangeChecksum{Range: r}
+	if err := tx.QueryRow(query, args...).Scan(&checksum.RowCount, &checksum.Hash); err != nil {
+		return nil, fmt.Errorf("failed to compute range checksum: %w", err)
+	}
+
+	return checksum, nil
+}
+
+// GetRangeSplitPoints returns up to parts-1 key values that split a key range into parts of
+// roughly equal row count. Each value is the text form of the key columns of a boundary row.
+func (c *Connection) GetRangeSplitPoints(schema, tableName, where string, keyColumns []string, r models.KeyRange, rowCount int64, parts int) ([][]string, error) {
+	if parts < 2 || rowCount < 2 {
+		return nil, nil
+	}
+
+	step := (rowCount + int64(parts) - 1) / int64(parts)
+	cond, args := keyRangeCondition(keyColumns, r, where, 2)
+
+	keys := quotedColumns(keyColumns)
+	keySelect := make([]string, len(keys))
+	keyNames := make([]string, len(keys))
+	for i, key := range keys {
+		keyNames[i] = fmt.Sprintf("%s%d", streamKeyPrefix, i)
+		keySelect[i] = fmt.Sprintf("(%s)::text AS %s", key, keyNames[i])
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM (
+			SELECT %s, row_number() OVER (ORDER BY %s) AS __dc_rn
+			FROM %s t
+			WHERE %s
+		) s
+		WHERE __dc_rn > 1 AND (__dc_rn - 1) %% $1 = 0
+		ORDER BY __dc_rn`,
+		strings.Join(keyNames, ", "), strings.Join(keySelect, ", "), strings.Join(keys, ", "),
+		qualifiedTable(schema, tableName), cond)
+
+	// Split points are used as bounds on the other server, so they are rendered under fixed settings
+	tx, err := c.beginRenderingTx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query, append([]interface{}{step}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query range split points: %w", err)
+	}
+	defer rows.Close()
+
+	var points [][]string
+	for rows.Next() {
+		values := make([]string, len(keyColumns))
+		valuePtrs := make([]interface{}, len(keyColumns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan split point: %w", err)
+		}
+		points = append(points, values)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating split points: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetRangeRows retrieves all rows of a key range
+func (c *Connection) GetRangeRows(schema, tableName, where string, keyColumns []string, r models.KeyRange) ([]models.TableRow, error) {
+	cond, args := keyRangeCondition(keyColumns, r, where, 1)
+	query := fmt.Sprintf("SELECT t.* FROM %s t WHERE %s", qualifiedTable(schema, tableName), cond)
+
+	// The bounds were rendered by beginRenderingTx, so they are read back under the same settings
+	tx, err := c.beginRenderingTx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query range rows: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	result := []models.TableRow{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		row := make(models.TableRow)
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating range rows: %w", err)
+	}
+
+	return result, nil
+}
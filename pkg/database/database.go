@@ -64,17 +64,14 @@ func (c *Connection) GetTableSchema(schema, tableName string) (*models.TableSche
 		SELECT 
 			c.column_name, 
 			c.data_type, 
+			c.udt_name,
 			c.is_nullable = 'YES' as is_nullable,
-			CASE WHEN pk.column_name IS NOT NULL THEN true ELSE false END as is_primary
+			format_type(a.atttypid, a.atttypmod) as formatted_type,
+			COALESCE(c.column_default, '') as column_default
 		FROM information_schema.columns c
-		LEFT JOIN (
-			SELECT ku.column_name
-			FROM information_schema.table_constraints tc
-			JOIN information_schema.key_column_usage ku ON tc.constraint_name = ku.constraint_name
-			WHERE tc.constraint_type = 'PRIMARY KEY' 
-			AND tc.table_schema = $1 
-			AND tc.table_name = $2
-		) pk ON c.column_name = pk.column_name
+		JOIN pg_catalog.pg_namespace n ON n.nspname = c.table_schema
+		JOIN pg_catalog.pg_class t ON t.relnamespace = n.oid AND t.relname = c.table_name
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attname = c.column_name
 		WHERE c.table_schema = $1 AND c.table_name = $2
 		ORDER BY c.ordinal_position`
 
@@ -86,12 +83,21 @@ func (c *Connection) GetTableSchema(schema, tableName string) (*models.TableSche
 
 	for rows.Next() {
 		var col models.ColumnInfo
-		if err := rows.Scan(&col.ColumnName, &col.DataType, &col.IsNullable, &col.IsPrimary); err != nil {
+		if err := rows.Scan(&col.ColumnName, &col.DataType, &col.UDTName, &col.IsNullable, &col.FormattedType, &col.Default); err != nil {
 			return nil, fmt.Errorf("failed to scan column info: %w", err)
 		}
 		tableSchema.Columns = append(tableSchema.Columns, col)
 	}
 
+	// Get primary and unique keys
+	tableSchema.PrimaryKey, tableSchema.UniqueKeys, err = c.GetTableKeys(schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tableSchema.Columns {
+		tableSchema.Columns[i].IsPrimary = tableSchema.IsPrimaryKey(tableSchema.Columns[i].ColumnName)
+	}
+
 	// Get foreign key information
 	fkQuery := `
 		SELECT 
@@ -128,17 +134,18 @@ func (c *Connection) GetTableSchema(schema, tableName string) (*models.TableSche
 	return tableSchema, nil
 }
 
-// GetTableData retrieves all data from a table with progress indication
-func (c *Connection) GetTableData(schema, tableName string) (*models.TableData, error) {
+// GetTableData retrieves all data from a table with progress indication.
+// A non-empty where predicate restricts the rows that are counted and loaded.
+func (c *Connection) GetTableData(schema, tableName, where string) (*models.TableData, error) {
 	// First, get row count for progress bar
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", schema, tableName)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s%s", schema, tableName, whereClause(where))
 	var totalRows int64
 	err := c.DB.QueryRow(countQuery).Scan(&totalRows)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get row count: %w", err)
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s.%s", schema, tableName)
+	query := fmt.Sprintf("SELECT * FROM %s.%s%s", schema, tableName, whereClause(where))
 
 	rows, err := c.DB.Query(query)
 	if err != nil {
@@ -195,6 +202,14 @@ func (c *Connection) GetTableData(schema, tableName string) (*models.TableData,
 	return tableData, nil
 }
 
+// whereClause returns a WHERE clause for a user supplied row filter, or an empty string when there is none
+func whereClause(where string) string {
+	if strings.TrimSpace(where) == "" {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE (%s)", where)
+}
+
 // GetForeignKeyData retrieves data from a foreign key referenced table based on specific values
 func (c *Connection) GetForeignKeyData(fk models.ForeignKey, values []interface{}) ([]models.TableRow, error) {
 	if len(values) == 0 {
@@ -265,6 +280,40 @@ func (c *Connection) TableExists(schema, tableName string) (bool, error) {
 	return exists, nil
 }
 
+// ListTables returns the names of the tables of a schema in alphabetical order. Partitions are
+// left out, since comparing a partitioned table covers them.
+func (c *Connection) ListTables(schema string) ([]string, error) {
+	query := `
+		SELECT c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+			AND c.relkind IN ('r', 'p')
+			AND NOT c.relispartition
+		ORDER BY c.relname`
+
+	rows, err := c.DB.Query(query, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table rows: %w", err)
+	}
+
+	return tables, nil
+}
+
 // GetReferencingTables finds all tables that have foreign keys pointing to the specified table/column
 // Uses hybrid approach: formal FK constraints first, then column pattern detection
 func (c *Connection) GetReferencingTables(targetSchema, targetTable, targetColumn string) ([]models.ForeignKey, error) {
@@ -338,8 +387,12 @@ func (c *Connection) GetReferencingTables(targetSchema, targetTable, targetColum
 	// This handles cases where FK relationships exist at the data level but formal constraints are not defined
 	if len(foreignKeys) == 0 {
 		// Search for columns that might reference this table based on naming conventions
-		// For now, focus on the primary key column (usually 'id') being referenced by table_name + '_id'
-		if targetColumn == "id" {
+		// For now, focus on a single-column primary key being referenced by table_name + '_id'
+		primaryKey, _, err := c.GetTableKeys(targetSchema, targetTable)
+		if err != nil {
+			return nil, err
+		}
+		if len(primaryKey) == 1 && primaryKey[0] == targetColumn {
 			potentialFKQuery := `
 				SELECT DISTINCT 
 					c.column_name,
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"fmt"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// GetObjectInventory lists the tables, views, materialized views, sequences, functions, types and
+// extensions of the database with an MD5 hash of their definitions. System schemas, partitions and
+// objects that belong to an extension are skipped. When schemas is empty every schema is listed;
+// extensions are database-wide and always listed, under the schema they were installed in.
+func (c *Connection) GetObjectInventory(schemas []string) ([]models.DatabaseObject, error) {
+	query := `
+		WITH user_namespaces AS (
+			SELECT oid, nspname
+			FROM pg_catalog.pg_namespace
+			WHERE nspname NOT IN ('pg_catalog', 'information_schema')
+				AND nspname NOT LIKE 'pg_toast%'
+				AND nspname NOT LIKE 'pg_temp_%'
+				AND (COALESCE(cardinality($1::text[]), 0) = 0 OR nspname = ANY($1::text[]))
+		),
+		extension_members AS (
+			SELECT classid, objid
+			FROM pg_catalog.pg_depend
+			WHERE deptype = 'e'
+		)
+		SELECT
+			CASE c.relkind
+				WHEN 'r' THEN 'table'
+				WHEN 'p' THEN 'table'
+				WHEN 'v' THEN 'view'
+				WHEN 'm' THEN 'materialized view'
+				WHEN 'S' THEN 'sequence'
+			END,
+			n.nspname,
+			c.relname,
+			md5(COALESCE(CASE
+				WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid, true)
+				WHEN c.relkind = 'S' THEN (
+					SELECT concat_ws(' ', format_type(s.seqtypid, NULL), s.seqstart, s.seqincrement,
+						s.seqmin, s.seqmax, s.seqcache, s.seqcycle)
+					FROM pg_catalog.pg_sequence s
+					WHERE s.seqrelid = c.oid)
+				ELSE (
+					SELECT string_agg(a.attname || ' ' || format_type(a.atttypid, a.atttypmod) ||
+						CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END, ', ' ORDER BY a.attnum)
+					FROM pg_catalog.pg_attribute a
+					WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped)
+			END, '')),
+			''
+		FROM pg_catalog.pg_class c
+		JOIN user_namespaces n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p', 'v', 'm', 'S')
+			AND NOT c.relispartition
+			AND NOT EXISTS (
+				SELECT 1 FROM extension_members e
+				WHERE e.classid = 'pg_catalog.pg_class'::regclass AND e.objid = c.oid)
+
+		UNION ALL
+
+		SELECT
+			'function',
+			n.nspname,
+			p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+			md5(CASE WHEN p.prokind IN ('f', 'p') THEN pg_get_functiondef(p.oid) ELSE p.prosrc END),
+			''
+		FROM pg_catalog.pg_proc p
+		JOIN user_namespaces n ON n.oid = p.pronamespace
+		WHERE NOT EXISTS (
+			SELECT 1 FROM extension_members e
+			WHERE e.classid = 'pg_catalog.pg_proc'::regclass AND e.objid = p.oid)
+
+		UNION ALL
+
+		SELECT
+			'type',
+			n.nspname,
+			t.typname,
+			md5(COALESCE(CASE t.typtype
+				WHEN 'e' THEN 'enum ' || (
+					SELECT string_agg(quote_literal(en.enumlabel), ', ' ORDER BY en.enumsortorder)
+					FROM pg_catalog.pg_enum en
+					WHERE en.enumtypid = t.oid)
+				WHEN 'd' THEN concat_ws(' ', 'domain', format_type(t.typbasetype, t.typtypmod),
+					CASE WHEN t.typnotnull THEN 'NOT NULL' END, t.typdefault, (
+						SELECT string_agg(pg_get_constraintdef(con.oid, true), ' ' ORDER BY con.conname)
+						FROM pg_catalog.pg_constraint con
+						WHERE con.contypid = t.oid))
+				WHEN 'r' THEN 'range ' || (
+					SELECT format_type(r.rngsubtype, NULL)
+					FROM pg_catalog.pg_range r
+					WHERE r.rngtypid = t.oid)
+				ELSE 'composite ' || (
+					SELECT string_agg(a.attname || ' ' || format_type(a.atttypid, a.atttypmod), ', ' ORDER BY a.attnum)
+					FROM pg_catalog.pg_attribute a
+					WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped)
+			END, '')),
+			''
+		FROM pg_catalog.pg_type t
+		JOIN user_namespaces n ON n.oid = t.typnamespace
+		WHERE (t.typtype IN ('e', 'd', 'r')
+				OR (t.typtype = 'c' AND EXISTS (
+					SELECT 1 FROM pg_catalog.pg_class tc
+					WHERE tc.oid = t.typrelid AND tc.relkind = 'c')))
+			AND NOT EXISTS (
+				SELECT 1 FROM extension_members e
+				WHERE e.classid = 'pg_catalog.pg_type'::regclass AND e.objid = t.oid)
+
+		UNION ALL
+
+		SELECT
+			'extension',
+			n.nspname,
+			x.extname,
+			md5(x.extversion),
+			x.extversion
+		FROM pg_catalog.pg_extension x
+		JOIN pg_catalog.pg_namespace n ON n.oid = x.extnamespace
+
+		ORDER BY 1, 2, 3`
+
+	rows, err := c.DB.Query(query, pq.Array(schemas))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object inventory: %w", err)
+	}
+	defer rows.Close()
+
+	objects := []models.DatabaseObject{}
+	for rows.Next() {
+		var object models.DatabaseObject
+		if err := rows.Scan(&object.ObjectType, &object.Schema, &object.Name, &object.DefinitionHash, &object.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory object: %w", err)
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inventory rows: %w", err)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// GetTableKeys retrieves the primary key and the unique keys of a table from pg_catalog.
+// Unique keys come from unique constraints and unique indexes; partial and expression
+// indexes are skipped because they do not identify every row. Columns are in key order.
+func (c *Connection) GetTableKeys(schema, tableName string) ([]string, []models.UniqueKey, error) {
+	query := `
+		SELECT
+			ic.relname,
+			ix.indisprimary,
+			array_agg(a.attname::text ORDER BY k.ord)
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_class ic ON ic.oid = ix.indexrelid
+		CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1
+			AND t.relname = $2
+			AND ix.indisunique
+			AND ix.indpred IS NULL
+			AND ix.indexprs IS NULL
+			AND k.ord <= ix.indnkeyatts
+		GROUP BY ic.relname, ix.indisprimary
+		ORDER BY ix.indisprimary DESC, ic.relname`
+
+	rows, err := c.DB.Query(query, schema, tableName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get key information: %w", err)
+	}
+	defer rows.Close()
+
+	var primaryKey []string
+	var uniqueKeys []models.UniqueKey
+	for rows.Next() {
+		var name string
+		var primary bool
+		var columns []string
+		if err := rows.Scan(&name, &primary, pq.Array(&columns)); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan key info: %w", err)
+		}
+
+		if primary {
+			primaryKey = columns
+		} else {
+			uniqueKeys = append(uniqueKeys, models.UniqueKey{Name: name, Columns: columns})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating key rows: %w", err)
+	}
+
+	return primaryKey, uniqueKeys, nil
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"deepComparator/pkg/models"
+)
+
+// GetTableConstraints retrieves the primary key, unique, check, foreign key and exclusion
+// constraints of a table from pg_constraint. NOT NULL constraints are reported as column
+// nullability by GetTableSchema and are skipped here.
+func (c *Connection) GetTableConstraints(schema, tableName string) ([]models.TableObject, error) {
+	query := `
+		SELECT
+			con.conname,
+			CASE con.contype
+				WHEN 'p' THEN 'PRIMARY KEY'
+				WHEN 'u' THEN 'UNIQUE'
+				WHEN 'c' THEN 'CHECK'
+				WHEN 'f' THEN 'FOREIGN KEY'
+				WHEN 'x' THEN 'EXCLUDE'
+				ELSE con.contype::text
+			END,
+			pg_get_constraintdef(con.oid, true)
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+			AND t.relname = $2
+			AND con.contype <> 'n'
+		ORDER BY con.conname`
+
+	rows, err := c.DB.Query(query, schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get constraints: %w", err)
+	}
+	return scanTableObjects(rows)
+}
+
+// GetTableIndexes retrieves the indexes of a table and their definitions from pg_indexes
+func (c *Connection) GetTableIndexes(schema, tableName string) ([]models.TableObject, error) {
+	query := `
+		SELECT indexname, '', indexdef
+		FROM pg_catalog.pg_indexes
+		WHERE schemaname = $1 AND tablename = $2
+		ORDER BY indexname`
+
+	rows, err := c.DB.Query(query, schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	return scanTableObjects(rows)
+}
+
+// GetTableTriggers retrieves the user-defined triggers of a table from pg_trigger,
+// with their firing state (ENABLED, DISABLED, REPLICA or ALWAYS) as kind
+func (c *Connection) GetTableTriggers(schema, tableName string) ([]models.TableObject, error) {
+	query := `
+		SELECT
+			tg.tgname,
+			CASE tg.tgenabled
+				WHEN 'O' THEN 'ENABLED'
+				WHEN 'D' THEN 'DISABLED'
+				WHEN 'R' THEN 'REPLICA'
+				WHEN 'A' THEN 'ALWAYS'
+				ELSE tg.tgenabled::text
+			END,
+			pg_get_triggerdef(tg.oid, true)
+		FROM pg_catalog.pg_trigger tg
+		JOIN pg_catalog.pg_class t ON t.oid = tg.tgrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+			AND t.relname = $2
+			AND NOT tg.tgisinternal
+		ORDER BY tg.tgname`
+
+	rows, err := c.DB.Query(query, schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get triggers: %w", err)
+	}
+	return scanTableObjects(rows)
+}
+
+// scanTableObjects reads name, kind and definition rows into table objects and closes the rows
+func scanTableObjects(rows *sql.Rows) ([]models.TableObject, error) {
+	defer rows.Close()
+
+	objects := []models.TableObject{}
+	for rows.Next() {
+		var object models.TableObject
+		if err := rows.Scan(&object.Name, &object.Kind, &object.Definition); err != nil {
+			return nil, fmt.Errorf("failed to scan object definition: %w", err)
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating object rows: %w", err)
+	}
+
+	return objects, nil
+}
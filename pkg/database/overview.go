@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// GetTableStats lists the tables of the database with their planner row estimate (-1 when the
+// table, or every partition of a partitioned table, was never analyzed), total on-disk size
+// including indexes and TOAST, and last manual or automatic analyze time. Partitions are counted
+// in their parent table, never-analyzed partitions counting as empty next to analyzed ones. When schemas is empty
+// the tables of every user schema are listed.
+func (c *Connection) GetTableStats(schemas []string) ([]models.TableInfo, error) {
+	query := `
+		WITH tables AS (
+			SELECT
+				n.nspname,
+				c.relname,
+				CASE
+					WHEN c.relkind = 'p' THEN (
+						SELECT COALESCE(CASE
+							WHEN bool_and(pc.reltuples < 0) THEN -1
+							ELSE sum(GREATEST(pc.reltuples, 0))
+						END, 0)::bigint
+						FROM pg_catalog.pg_partition_tree(c.oid) pt
+						JOIN pg_catalog.pg_class pc ON pc.oid = pt.relid
+						WHERE pt.isleaf)
+					ELSE c.reltuples::bigint
+				END AS estimated_rows,
+				CASE
+					WHEN c.relkind = 'p' THEN (
+						SELECT COALESCE(sum(pg_catalog.pg_total_relation_size(pt.relid)), 0)::bigint
+						FROM pg_catalog.pg_partition_tree(c.oid) pt)
+					ELSE pg_catalog.pg_total_relation_size(c.oid)
+				END AS total_bytes,
+				GREATEST(st.last_analyze, st.last_autoanalyze) AS last_analyzed
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_catalog.pg_stat_all_tables st ON st.relid = c.oid
+			WHERE c.relkind IN ('r', 'p')
+				AND NOT c.relispartition
+				AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+				AND n.nspname NOT LIKE 'pg_toast%'
+				AND n.nspname NOT LIKE 'pg_temp_%'
+				AND (COALESCE(cardinality($1::text[]), 0) = 0 OR n.nspname = ANY($1::text[]))
+		)
+		SELECT nspname, relname, estimated_rows, total_bytes, pg_catalog.pg_size_pretty(total_bytes), last_analyzed
+		FROM tables
+		ORDER BY nspname, relname`
+
+	rows, err := c.DB.Query(query, pq.Array(schemas))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table stats: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []models.TableInfo{}
+	for rows.Next() {
+		var table models.TableInfo
+		var lastAnalyzed sql.NullTime
+		if err := rows.Scan(&table.Schema, &table.TableName, &table.RowCount, &table.TotalBytes, &table.TotalSize, &lastAnalyzed); err != nil {
+			return nil, fmt.Errorf("failed to scan table stats: %w", err)
+		}
+		if lastAnalyzed.Valid {
+			table.LastAnalyzed = &lastAnalyzed.Time
+		}
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table stats rows: %w", err)
+	}
+
+	return tables, nil
+}
+
+// GetExactRowCount counts the rows of a table
+func (c *Connection) GetExactRowCount(schema, tableName string) (int64, error) {
+	query := fmt.Sprintf("SELECT count(*) FROM %s", qualifiedTable(schema, tableName))
+
+	var count int64
+	if err := c.DB.QueryRow(query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s.%s: %w", schema, tableName, err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"deepComparator/pkg/models"
+)
+
+// Supported row sampling methods
+const (
+	SampleMethodRandom    = "random"    // WHERE random() < p, exact per-row probability, scans the whole table
+	SampleMethodBernoulli = "bernoulli" // TABLESAMPLE BERNOULLI, per-row probability, cheaper than random()
+	SampleMethodSystem    = "system"    // TABLESAMPLE SYSTEM, samples whole pages, fastest but clustered
+)
+
+// maxLookupKeys bounds the number of keys looked up per query
+const maxLookupKeys = 500
+
+// GetSampleRows retrieves a random sample of roughly percent % of the rows matching the optional
+// where predicate, together with the text form of their key expressions over the table alias t
+func (c *Connection) GetSampleRows(schema, tableName, where, method string, percent float64, keyExprs []string) ([]StreamedRow, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("sample percentage must be in (0, 100], got %g", percent)
+	}
+
+	table := qualifiedTable(schema, tableName)
+
+	keySelect := make([]string, len(keyExprs))
+	for i, key := range keyExprs {
+		keySelect[i] = fmt.Sprintf("(%s)::text AS %s%d", key, streamKeyPrefix, i)
+	}
+	selectList := strings.Join(append([]string{"t.*"}, keySelect...), ", ")
+
+	var query string
+	switch method {
+	case SampleMethodRandom:
+		conds := []string{fmt.Sprintf("random() < %g", percent/100)}
+		if strings.TrimSpace(where) != "" {
+			conds = append(conds, "("+where+")")
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s t WHERE %s", selectList, table, strings.Join(conds, " AND "))
+	case SampleMethodBernoulli, SampleMethodSystem:
+		query = fmt.Sprintf("SELECT %s FROM %s t TABLESAMPLE %s (%g)%s",
+			selectList, table, strings.ToUpper(method), percent, whereClause(where))
+	default:
+		return nil, fmt.Errorf("unknown sample method %q (use %s, %s or %s)",
+			method, SampleMethodRandom, SampleMethodBernoulli, SampleMethodSystem)
+	}
+
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sample rows: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	var sample []StreamedRow
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan sample row: %w", err)
+		}
+		sample = append(sample, *splitKeyValues(columns, values, len(keyExprs)))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sample rows: %w", err)
+	}
+
+	return sample, nil
+}
+
+// GetRowsByKey retrieves the rows whose key expressions over the table alias t equal one of the
+// given key values. Each key holds one value per key expression; keys are looked up in batches.
+// Plain column references are compared on the native column types, with values sent as text and
+// cast by the server, so that their indexes can be used.
+func (c *Connection) GetRowsByKey(schema, tableName, where string, keyExprs []string, keys [][]string) ([]models.TableRow, error) {
+	result := []models.TableRow{}
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	keyRow := fmt.Sprintf("ROW(%s)", strings.Join(keyExprs, ", "))
+	table := qualifiedTable(schema, tableName)
+
+	for start := 0; start < len(keys); start += maxLookupKeys {
+		end := start + maxLookupKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var args []interface{}
+		tuples := make([]string, 0, end-start)
+		for _, key := range keys[start:end] {
+			placeholders := make([]string, len(key))
+			for i, value := range key {
+				args = append(args, value)
+				placeholders[i] = fmt.Sprintf("$%d", len(args))
+			}
+			tuples = append(tuples, fmt.Sprintf("ROW(%s)", strings.Join(placeholders, ", ")))
+		}
+
+		cond := fmt.Sprintf("%s IN (%s)", keyRow, strings.Join(tuples, ", "))
+		if strings.TrimSpace(where) != "" {
+			cond = fmt.Sprintf("(%s) AND %s", where, cond)
+		}
+
+		rows, err := c.DB.Query(fmt.Sprintf("SELECT t.* FROM %s t WHERE %s", table, cond), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query rows by key: %w", err)
+		}
+
+		batch, err := scanTableRows(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch...)
+	}
+
+	return result, nil
+}
+
+// GetEstimatedRowCount returns the planner's row count estimate of a table, or -1 when the table has never been analyzed
+func (c *Connection) GetEstimatedRowCount(schema, tableName string) (int64, error) {
+	query := `
+		SELECT c.reltuples::bigint
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2`
+
+	var estimate int64
+	if err := c.DB.QueryRow(query, schema, tableName).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("failed to get estimated row count: %w", err)
+	}
+
+	return estimate, nil
+}
+
+// scanTableRows reads all rows of a result set into table rows
+func scanTableRows(rows *sql.Rows) ([]models.TableRow, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	result := []models.TableRow{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		row := make(models.TableRow)
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
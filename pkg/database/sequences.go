@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"deepComparator/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// GetSequences retrieves the sequences of the database from pg_sequences with the column that
+// owns them: the serial column (OWNED BY) or the identity column. When schemas is empty the
+// sequences of every user schema are listed. The last value and is_called flag are read from each
+// sequence itself, since pg_sequences hides the last value of a sequence reset with
+// setval(seq, n, false) just like that of an unused one. The next value of an exhausted sequence
+// is reported as its limit. The column bound is not filled in.
+func (c *Connection) GetSequences(schemas []string) ([]models.SequenceInfo, error) {
+	query := `
+		SELECT
+			s.schemaname,
+			s.sequencename,
+			COALESCE(t.relname, ''),
+			COALESCE(a.attname, ''),
+			COALESCE(d.deptype = 'i', false),
+			s.increment_by,
+			s.min_value,
+			s.max_value
+		FROM pg_catalog.pg_sequences s
+		JOIN pg_catalog.pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = s.sequencename
+		LEFT JOIN pg_catalog.pg_depend d
+			ON d.classid = 'pg_catalog.pg_class'::regclass
+			AND d.objid = c.oid
+			AND d.refclassid = 'pg_catalog.pg_class'::regclass
+			AND d.refobjsubid > 0
+			AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_catalog.pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE s.schemaname NOT IN ('pg_catalog', 'information_schema')
+			AND (COALESCE(cardinality($1::text[]), 0) = 0 OR s.schemaname = ANY($1::text[]))
+		ORDER BY s.schemaname, s.sequencename`
+
+	rows, err := c.DB.Query(query, pq.Array(schemas))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := []models.SequenceInfo{}
+	for rows.Next() {
+		var seq models.SequenceInfo
+		if err := rows.Scan(&seq.Schema, &seq.SequenceName, &seq.TableName, &seq.ColumnName, &seq.Identity,
+			&seq.IncrementBy, &seq.MinValue, &seq.MaxValue); err != nil {
+			return nil, fmt.Errorf("failed to scan sequence info: %w", err)
+		}
+		sequences = append(sequences, seq)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sequence rows: %w", err)
+	}
+	rows.Close()
+
+	for i := range sequences {
+		if err := c.readSequenceState(&sequences[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return sequences, nil
+}
+
+// readSequenceState reads the last value of a sequence and derives the value the next nextval()
+// call returns: the last value itself until it has been called, else the following one
+func (c *Connection) readSequenceState(seq *models.SequenceInfo) error {
+	var lastValue int64
+	var isCalled bool
+	query := fmt.Sprintf("SELECT last_value, is_called FROM %s", qualifiedTable(seq.Schema, seq.SequenceName))
+	if err := c.DB.QueryRow(query).Scan(&lastValue, &isCalled); err != nil {
+		return fmt.Errorf("failed to read sequence %s.%s: %w", seq.Schema, seq.SequenceName, err)
+	}
+
+	if !isCalled {
+		seq.NextValue = lastValue
+		return nil
+	}
+
+	seq.LastValue = &lastValue
+	switch {
+	case seq.IncrementBy > 0 && lastValue > seq.MaxValue-seq.IncrementBy:
+		seq.NextValue = seq.MaxValue
+	case seq.IncrementBy < 0 && lastValue < seq.MinValue-seq.IncrementBy:
+		seq.NextValue = seq.MinValue
+	default:
+		seq.NextValue = lastValue + seq.IncrementBy
+	}
+	return nil
+}
+
+// GetColumnBound returns the highest value of an integer column, or the lowest when lowest is set.
+// It returns nil when the table is empty.
+func (c *Connection) GetColumnBound(schema, tableName, columnName string, lowest bool) (*int64, error) {
+	aggregate := "MAX"
+	if lowest {
+		aggregate = "MIN"
+	}
+	query := fmt.Sprintf("SELECT %s(%s)::bigint FROM %s", aggregate,
+		pq.QuoteIdentifier(columnName), qualifiedTable(schema, tableName))
+
+	var bound sql.NullInt64
+	if err := c.DB.QueryRow(query).Scan(&bound); err != nil {
+		return nil, fmt.Errorf("failed to get %s of %s.%s.%s: %w", aggregate, schema, tableName, columnName, err)
+	}
+	if !bound.Valid {
+		return nil, nil
+	}
+	return &bound.Int64, nil
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"deepComparator/pkg/models"
+)
+
+// DefaultStreamBatchSize is the number of rows fetched from a cursor per round trip
+const DefaultStreamBatchSize = 1000
+
+// streamKeyPrefix prefixes the helper columns that carry the text form of the sort key
+const streamKeyPrefix = "__dc_key_"
+
+// cursorCounter makes cursor names unique within the process
+var cursorCounter uint64
+
+// StreamedRow is a single row read from a RowStream together with its sort key.
+// Key holds the value of each key expression, in the same order used by ORDER BY.
+type StreamedRow struct {
+	Row models.TableRow
+	Key []sql.NullString
+}
+
+// RowStream reads table rows in key order through a server-side cursor,
+// keeping at most one batch of rows in memory at a time
+type RowStream struct {
+	TotalRows int64
+
+	tx        *sql.Tx
+	rows      *sql.Rows
+	cursor    string
+	columns   []string
+	keyCount  int
+	batchSize int
+	batchRows int
+	exhausted bool
+}
+
+// StreamTableData opens a read-only snapshot and a cursor over a table ordered by the given key
+// expressions, optionally restricted by a where predicate. Each key expression is a text-valued SQL
+// expression over the table alias t. Keys are ordered using the "C" collation so that the order is
+// identical on both databases and can be reproduced byte-wise in Go. Rows sharing a key are further
+// ordered by the tiebreak expressions, which are not returned.
+func (c *Connection) StreamTableData(schema, tableName, where string, keyExprs, tiebreakExprs []string, batchSize int) (*RowStream, error) {
+	if len(keyExprs) == 0 {
+		return nil, fmt.Errorf("at least one key column is required to stream %s.%s", schema, tableName)
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+
+	tx, err := c.DB.BeginTx(context.Background(), &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin streaming transaction: %w", err)
+	}
+
+	table := qualifiedTable(schema, tableName)
+
+	var totalRows int64
+	if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s t%s", table, whereClause(where))).Scan(&totalRows); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get row count: %w", err)
+	}
+
+	sortExprs := make([]string, len(keyExprs))
+	keySelect := make([]string, len(keyExprs))
+	for i, expr := range keyExprs {
+		sortExprs[i] = fmt.Sprintf(`(%s) COLLATE "C"`, expr)
+		keySelect[i] = fmt.Sprintf("%s AS %s%d", sortExprs[i], streamKeyPrefix, i)
+	}
+
+	orderBy := make([]string, 0, len(sortExprs)+len(tiebreakExprs))
+	for _, expr := range sortExprs {
+		orderBy = append(orderBy, expr+" NULLS LAST")
+	}
+	for _, expr := range tiebreakExprs {
+		orderBy = append(orderBy, fmt.Sprintf(`(%s) COLLATE "C" NULLS LAST`, expr))
+	}
+
+	cursor := fmt.Sprintf("dc_cursor_%d", atomic.AddUint64(&cursorCounter, 1))
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR SELECT t.*, %s FROM %s t%s ORDER BY %s",
+		cursor, strings.Join(keySelect, ", "), table, whereClause(where), strings.Join(orderBy, ", "))
+
+	if _, err := tx.Exec(declare); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	return &RowStream{
+		TotalRows: totalRows,
+		tx:        tx,
+		cursor:    cursor,
+		keyCount:  len(keyExprs),
+		batchSize: batchSize,
+	}, nil
+}
+
+// Next returns the next row of the stream, or io.EOF when the cursor is exhausted
+func (s *RowStream) Next() (*StreamedRow, error) {
+	for {
+		if s.rows == nil {
+			if s.exhausted {
+				return nil, io.EOF
+			}
+			if err := s.fetch(); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.rows.Next() {
+			s.batchRows++
+			return s.scan()
+		}
+
+		if err := s.rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating cursor rows: %w", err)
+		}
+		s.rows.Close()
+		s.rows = nil
+
+		// A short batch means the cursor has no more rows
+		if s.batchRows < s.batchSize {
+			s.exhausted = true
+		}
+	}
+}
+
+// Close releases the cursor and its snapshot
+func (s *RowStream) Close() error {
+	if s.rows != nil {
+		s.rows.Close()
+		s.rows = nil
+	}
+	if s.tx == nil {
+		return nil
+	}
+	err := s.tx.Rollback()
+	s.tx = nil
+	return err
+}
+
+// fetch requests the next batch of rows from the cursor
+func (s *RowStream) fetch() error {
+	rows, err := s.tx.Query(fmt.Sprintf("FETCH FORWARD %d FROM %s", s.batchSize, s.cursor))
+	if err != nil {
+		return fmt.Errorf("failed to fetch from cursor: %w", err)
+	}
+
+	if s.columns == nil {
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to get columns: %w", err)
+		}
+		s.columns = columns
+	}
+
+	s.rows = rows
+	s.batchRows = 0
+	return nil
+}
+
+// scan reads the current cursor row, splitting the table columns from the sort key
+func (s *RowStream) scan() (*StreamedRow, error) {
+	values := make([]interface{}, len(s.columns))
+	valuePtrs := make([]interface{}, len(s.columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := s.rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return splitKeyValues(s.columns, values, s.keyCount), nil
+}
+
+// splitKeyValues splits a scanned row whose last keyCount columns carry the text form of the key
+func splitKeyValues(columns []string, values []interface{}, keyCount int) *StreamedRow {
+	dataColumns := len(columns) - keyCount
+	row := make(models.TableRow, dataColumns)
+	for i := 0; i < dataColumns; i++ {
+		row[columns[i]] = values[i]
+	}
+
+	key := make([]sql.NullString, keyCount)
+	for i := 0; i < keyCount; i++ {
+		switch v := values[dataColumns+i].(type) {
+		case nil:
+		case []byte:
+			key[i] = sql.NullString{String: string(v), Valid: true}
+		case string:
+			key[i] = sql.NullString{String: v, Valid: true}
+		default:
+			key[i] = sql.NullString{String: fmt.Sprintf("%v", v), Valid: true}
+		}
+	}
+
+	return &StreamedRow{Row: row, Key: key}
+}
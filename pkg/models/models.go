@@ -38,43 +38,232 @@ type ForeignKey struct {
 	ConstraintName       string `json:"constraint_name"`
 }
 
-// ColumnInfo represents column metadata
+// ColumnInfo represents column metadata.
+// FormattedType is the full type as PostgreSQL prints it, e.g. character varying(50) or numeric(10,2).
 type ColumnInfo struct {
-	ColumnName string `json:"column_name"`
-	DataType   string `json:"data_type"`
-	IsNullable bool   `json:"is_nullable"`
-	IsPrimary  bool   `json:"is_primary"`
+	ColumnName    string `json:"column_name"`
+	DataType      string `json:"data_type"`
+	UDTName       string `json:"udt_name,omitempty"`
+	IsNullable    bool   `json:"is_nullable"`
+	IsPrimary     bool   `json:"is_primary"`
+	FormattedType string `json:"formatted_type,omitempty"`
+	Default       string `json:"default,omitempty"`
+}
+
+// UniqueKey represents a unique constraint or unique index of a table
+type UniqueKey struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
 }
 
-// TableSchema represents table structure and metadata
+// TableSchema represents table structure and metadata.
+// PrimaryKey holds the primary key columns in key order; UniqueKeys holds the other unique keys.
 type TableSchema struct {
 	TableName   string       `json:"table_name"`
 	Schema      string       `json:"schema"`
 	Columns     []ColumnInfo `json:"columns"`
 	ForeignKeys []ForeignKey `json:"foreign_keys"`
+	PrimaryKey  []string     `json:"primary_key,omitempty"`
+	UniqueKeys  []UniqueKey  `json:"unique_keys,omitempty"`
+}
+
+// IsPrimaryKey reports whether a column is part of the primary key
+func (s *TableSchema) IsPrimaryKey(columnName string) bool {
+	for _, col := range s.PrimaryKey {
+		if col == columnName {
+			return true
+		}
+	}
+	return false
+}
+
+// NaturalKey returns the columns of the first unique key whose columns are all NOT NULL and
+// outside the primary key, or nil when there is none. Unlike surrogate IDs, such a key
+// identifies the same row in both databases.
+func (s *TableSchema) NaturalKey() []string {
+	nullable := make(map[string]bool)
+	for _, col := range s.Columns {
+		nullable[col.ColumnName] = col.IsNullable
+	}
+
+	for _, key := range s.UniqueKeys {
+		identifying := true
+		for _, col := range key.Columns {
+			if nullable[col] || s.IsPrimaryKey(col) {
+				identifying = false
+				break
+			}
+		}
+		if identifying {
+			return key.Columns
+		}
+	}
+	return nil
+}
+
+// RowKey returns the columns that identify a row: the primary key, else the natural key
+func (s *TableSchema) RowKey() []string {
+	if len(s.PrimaryKey) > 0 {
+		return s.PrimaryKey
+	}
+	return s.NaturalKey()
 }
 
 // ComparisonResult represents the result of comparing two tables
 type ComparisonResult struct {
-	TableName         string             `json:"table_name"`
-	Schema            string             `json:"schema"`
-	Timestamp         time.Time          `json:"timestamp"`
-	TotalRowsDB1      int                `json:"total_rows_db1"`
-	TotalRowsDB2      int                `json:"total_rows_db2"`
-	MatchedRows       int                `json:"matched_rows"`
-	UnmatchedRows     int                `json:"unmatched_rows"`
-	OnlyInDB1         []TableRow         `json:"only_in_db1"`
-	OnlyInDB2         []TableRow         `json:"only_in_db2"`
-	Differences       []RowDifference    `json:"differences"`
-	ForeignKeyResults []ForeignKeyResult `json:"foreign_key_results"`
+	TableName          string                   `json:"table_name"`
+	Schema             string                   `json:"schema"`
+	Timestamp          time.Time                `json:"timestamp"`
+	TotalRowsDB1       int                      `json:"total_rows_db1"`
+	TotalRowsDB2       int                      `json:"total_rows_db2"`
+	MatchedRows        int                      `json:"matched_rows"`
+	UnmatchedRows      int                      `json:"unmatched_rows"`
+	OnlyInDB1          []TableRow               `json:"only_in_db1"`
+	OnlyInDB2          []TableRow               `json:"only_in_db2"`
+	Differences        []RowDifference          `json:"differences"`
+	ForeignKeyResults  []ForeignKeyResult       `json:"foreign_key_results"`
+	ChildTables        []string                 `json:"child_tables,omitempty"`
+	DB2Schema          string                   `json:"db2_schema,omitempty"`
+	DB2TableName       string                   `json:"db2_table_name,omitempty"`
+	ColumnMap          map[string]string        `json:"column_map,omitempty"`
+	WhereDB1           string                   `json:"where_db1,omitempty"`
+	WhereDB2           string                   `json:"where_db2,omitempty"`
+	DuplicateKeys      []DuplicateKeyDifference `json:"duplicate_keys,omitempty"`
+	ProbableMatches    []ProbableMatch          `json:"probable_matches,omitempty"`
+	WithinTolerance    []RowDifference          `json:"within_tolerance,omitempty"`
+	NullEquivalentRows int                      `json:"null_equivalent_rows,omitempty"`
+	Streaming          *StreamingSummary        `json:"streaming,omitempty"`
+	Checksum           *ChecksumSummary         `json:"checksum,omitempty"`
+	Sampling           *SamplingSummary         `json:"sampling,omitempty"`
+}
+
+// SamplingSummary holds the estimate produced by a sampling comparison.
+// MismatchRate is the share of sampled DB1 rows that are missing from DB2 or differ there,
+// and ConfidenceLow/ConfidenceHigh bound it with a Wilson score interval.
+type SamplingSummary struct {
+	Method                  string   `json:"method"`
+	Percent                 float64  `json:"percent"`
+	KeyColumns              []string `json:"key_columns"`
+	SampledRows             int      `json:"sampled_rows"`
+	SkippedNullKeys         int      `json:"skipped_null_keys"`
+	MissingInDB2            int      `json:"missing_in_db2"`
+	RowsWithDifferences     int      `json:"rows_with_differences"`
+	MismatchedRows          int      `json:"mismatched_rows"`
+	MismatchRate            float64  `json:"mismatch_rate"`
+	ConfidenceLevel         float64  `json:"confidence_level"`
+	ConfidenceLow           float64  `json:"confidence_low"`
+	ConfidenceHigh          float64  `json:"confidence_high"`
+	EstimatedTableRows      int64    `json:"estimated_table_rows,omitempty"`
+	EstimatedMismatchedRows int64    `json:"estimated_mismatched_rows,omitempty"`
+}
+
+// ProbableMatch pairs a row found only in DB1 with a similar row found only in DB2.
+// Confidence is the share of compared columns holding equal values in both rows.
+type ProbableMatch struct {
+	Confidence float64 `json:"confidence"`
+	RowDifference
 }
 
-// RowDifference represents differences found between matching rows
+// DuplicateKeyDifference describes a match key whose number of occurrences differs between the databases.
+// Streaming comparisons keep only the first rows of each database in DB1Rows and DB2Rows.
+type DuplicateKeyDifference struct {
+	Key      string     `json:"key"`
+	CountDB1 int        `json:"count_db1"`
+	CountDB2 int        `json:"count_db2"`
+	DB1Rows  []TableRow `json:"db1_rows"`
+	DB2Rows  []TableRow `json:"db2_rows"`
+}
+
+// StreamingSummary holds the counters of a streaming comparison, whose rows are
+// written to a separate events file instead of being kept in the result
+type StreamingSummary struct {
+	EventsFile      string `json:"events_file,omitempty"`
+	OnlyInDB1       int    `json:"only_in_db1"`
+	OnlyInDB2       int    `json:"only_in_db2"`
+	Differences     int    `json:"differences"`
+	DuplicateKeys   int    `json:"duplicate_keys"`
+	WithinTolerance int    `json:"within_tolerance,omitempty"`
+	NullEquivalent  int    `json:"null_equivalent,omitempty"`
+}
+
+// ChecksumSummary holds the counters of a chunked checksum comparison
+type ChecksumSummary struct {
+	RangesCompared   int      `json:"ranges_compared"`
+	MatchingRanges   int      `json:"matching_ranges"`
+	MismatchedLeaves int      `json:"mismatched_leaves"`
+	RowsFetchedDB1   int      `json:"rows_fetched_db1"`
+	RowsFetchedDB2   int      `json:"rows_fetched_db2"`
+	KeyColumns       []string `json:"key_columns"`
+}
+
+// KeyRange is a half-open range [Lower, Upper) over the key columns of a table.
+// A nil bound is unbounded; NullKeys selects the rows whose key contains a NULL instead.
+type KeyRange struct {
+	Lower    []string `json:"lower,omitempty"`
+	Upper    []string `json:"upper,omitempty"`
+	NullKeys bool     `json:"null_keys,omitempty"`
+}
+
+// RangeChecksum represents the row count and aggregate hash of a key range
+type RangeChecksum struct {
+	Range    KeyRange `json:"range"`
+	RowCount int64    `json:"row_count"`
+	Hash     string   `json:"hash"`
+}
+
+// RangeChecksumPair holds the checksums of the same key range on both databases
+type RangeChecksumPair struct {
+	Range KeyRange       `json:"range"`
+	DB1   *RangeChecksum `json:"db1"`
+	DB2   *RangeChecksum `json:"db2"`
+}
+
+// Matches reports whether both databases hold the same rows in the range
+func (p RangeChecksumPair) Matches() bool {
+	return p.DB1.RowCount == p.DB2.RowCount && p.DB1.Hash == p.DB2.Hash
+}
+
+// StreamEvent is a single finding emitted by a streaming comparison
+type StreamEvent struct {
+	Type       string                  `json:"type"`
+	Row        TableRow                `json:"row,omitempty"`
+	Difference *RowDifference          `json:"difference,omitempty"`
+	Duplicate  *DuplicateKeyDifference `json:"duplicate,omitempty"`
+}
+
+// Stream event types
+const (
+	StreamEventOnlyInDB1  = "only_in_db1"
+	StreamEventOnlyInDB2  = "only_in_db2"
+	StreamEventDifference = "difference"
+	StreamEventDuplicate  = "duplicate_key"
+)
+
+// RowDifference represents differences found between matching rows.
+// ToleratedDifferences lists differences inside the configured column tolerances.
+// ChildDifferences lists, in an aggregate comparison, the child tables whose rows differ.
 type RowDifference struct {
-	RowIdentifier     string             `json:"row_identifier"`
-	DB1Row            TableRow           `json:"db1_row"`
-	DB2Row            TableRow           `json:"db2_row"`
-	ColumnDifferences []ColumnDifference `json:"column_differences"`
+	RowIdentifier         string             `json:"row_identifier"`
+	DB1Row                TableRow           `json:"db1_row"`
+	DB2Row                TableRow           `json:"db2_row"`
+	ColumnDifferences     []ColumnDifference `json:"column_differences"`
+	ToleratedDifferences  []ColumnDifference `json:"tolerated_differences,omitempty"`
+	NullEquivalentColumns []string           `json:"null_equivalent_columns,omitempty"`
+	ChildDifferences      []ChildDifference  `json:"child_differences,omitempty"`
+}
+
+// ChildDifference compares the child rows of a matched parent row in one table referencing the
+// parent. Child rows are paired as a set within the parent, ignoring the referencing column, which
+// holds the parent key of their own database.
+type ChildDifference struct {
+	Schema       string          `json:"schema"`
+	TableName    string          `json:"table_name"`
+	ColumnName   string          `json:"column_name"`
+	TotalRowsDB1 int             `json:"total_rows_db1"`
+	TotalRowsDB2 int             `json:"total_rows_db2"`
+	OnlyInDB1    []TableRow      `json:"only_in_db1,omitempty"`
+	OnlyInDB2    []TableRow      `json:"only_in_db2,omitempty"`
+	Differences  []RowDifference `json:"differences,omitempty"`
 }
 
 // ColumnDifference represents a difference in a specific column
@@ -84,6 +273,25 @@ type ColumnDifference struct {
 	DB2Value            interface{}          `json:"db2_value"`
 	IsForeignKey        bool                 `json:"is_foreign_key,omitempty"`
 	ForeignKeyReference *ForeignKeyReference `json:"foreign_key_reference,omitempty"`
+	JSONDiff            []JSONPathDifference `json:"json_diff,omitempty"`
+	ArrayDiff           *ArrayDifference     `json:"array_diff,omitempty"`
+}
+
+// ArrayDifference lists the elements of an array column present on one side only.
+// OrderChanged is set when an ordered array holds the same elements in a different order.
+type ArrayDifference struct {
+	Added        []interface{} `json:"added"`
+	Removed      []interface{} `json:"removed"`
+	OrderChanged bool          `json:"order_changed,omitempty"`
+}
+
+// JSONPathDifference describes a change at one path of a json/jsonb column.
+// Change is "changed", "added", "removed" or, when key order matters, "reordered".
+type JSONPathDifference struct {
+	Path     string      `json:"path"`
+	Change   string      `json:"change"`
+	DB1Value interface{} `json:"db1_value,omitempty"`
+	DB2Value interface{} `json:"db2_value,omitempty"`
 }
 
 // ForeignKeyReference represents the actual data referenced by a foreign key
@@ -94,21 +302,452 @@ type ForeignKeyReference struct {
 	ReferencedDiff bool       `json:"referenced_diff"`
 }
 
-// ForeignKeyResult represents the result of a foreign key comparison
+// SchemaComparisonResult represents the structural differences of a table between two databases.
+// Columns are named as in DB1; renamed DB2 columns are matched through the column map.
+type SchemaComparisonResult struct {
+	TableName          string                   `json:"table_name"`
+	Schema             string                   `json:"schema"`
+	DB2Schema          string                   `json:"db2_schema,omitempty"`
+	DB2TableName       string                   `json:"db2_table_name,omitempty"`
+	ColumnMap          map[string]string        `json:"column_map,omitempty"`
+	Timestamp          time.Time                `json:"timestamp"`
+	OnlyInDB1          []ColumnInfo             `json:"only_in_db1"`
+	OnlyInDB2          []ColumnInfo             `json:"only_in_db2"`
+	ColumnDifferences  []SchemaColumnDifference `json:"column_differences"`
+	ColumnOrderDiffers bool                     `json:"column_order_differs"`
+	ColumnOrderDB1     []string                 `json:"column_order_db1,omitempty"`
+	ColumnOrderDB2     []string                 `json:"column_order_db2,omitempty"`
+	ObjectDifferences  []ObjectDifference       `json:"object_differences"`
+}
+
+// SchemaColumnDifference represents a column attribute that differs between the two databases
+type SchemaColumnDifference struct {
+	ColumnName string `json:"column_name"`
+	Attribute  string `json:"attribute"` // data_type, nullable or default
+	DB1Value   string `json:"db1_value"`
+	DB2Value   string `json:"db2_value"`
+}
+
+// TableObject is a constraint, index or trigger of a table with its definition
+type TableObject struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind,omitempty"`
+	Definition string `json:"definition"`
+}
+
+// ObjectDifference represents a constraint, index or trigger that differs between the two databases.
+// Change is added when the object only exists in DB2, removed when it only exists in DB1,
+// and changed when its kind or definition differs.
+type ObjectDifference struct {
+	ObjectType    string `json:"object_type"` // constraint, index or trigger
+	Name          string `json:"name"`
+	Change        string `json:"change"`
+	DB1Kind       string `json:"db1_kind,omitempty"`
+	DB2Kind       string `json:"db2_kind,omitempty"`
+	DB1Definition string `json:"db1_definition,omitempty"`
+	DB2Definition string `json:"db2_definition,omitempty"`
+}
+
+// HasDifferences reports whether the two table structures disagree
+func (r *SchemaComparisonResult) HasDifferences() bool {
+	return len(r.OnlyInDB1) > 0 || len(r.OnlyInDB2) > 0 || len(r.ColumnDifferences) > 0 ||
+		r.ColumnOrderDiffers || len(r.ObjectDifferences) > 0
+}
+
+// DB1Target returns the compared table in DB1
+func (r *SchemaComparisonResult) DB1Target() TableTarget {
+	return TableTarget{Schema: r.Schema, Table: r.TableName}
+}
+
+// DB2Target returns the compared table in DB2, with its column map
+func (r *SchemaComparisonResult) DB2Target() TableTarget {
+	target := TableTarget{Schema: r.Schema, Table: r.TableName, ColumnMap: r.ColumnMap}
+	if r.DB2Schema != "" {
+		target.Schema = r.DB2Schema
+	}
+	if r.DB2TableName != "" {
+		target.Table = r.DB2TableName
+	}
+	return target
+}
+
+// DatabaseObject is an entry of a database object inventory: a table, view, materialized view,
+// sequence, function, type or extension. DefinitionHash is an MD5 of the object definition
+// (columns, view query, sequence parameters, function source, type shape or extension version).
+type DatabaseObject struct {
+	ObjectType     string `json:"object_type"`
+	Schema         string `json:"schema"`
+	Name           string `json:"name"` // functions include their argument types
+	DefinitionHash string `json:"definition_hash"`
+	Version        string `json:"version,omitempty"` // extensions only
+}
+
+// Key identifies an object across databases
+func (o DatabaseObject) Key() string {
+	return o.ObjectType + " " + o.Schema + "." + o.Name
+}
+
+// InventoryCount holds the number of objects of one type in each database
+type InventoryCount struct {
+	ObjectType string `json:"object_type"`
+	DB1        int    `json:"db1"`
+	DB2        int    `json:"db2"`
+}
+
+// InventoryDifference represents an object present in both databases with different definitions
+type InventoryDifference struct {
+	ObjectType string `json:"object_type"`
+	Schema     string `json:"schema"`
+	Name       string `json:"name"`
+	DB1Hash    string `json:"db1_hash"`
+	DB2Hash    string `json:"db2_hash"`
+	DB1Version string `json:"db1_version,omitempty"`
+	DB2Version string `json:"db2_version,omitempty"`
+}
+
+// InventoryComparisonResult represents the objects of two whole databases that exist in only
+// one of them or whose definitions differ
+type InventoryComparisonResult struct {
+	Timestamp             time.Time             `json:"timestamp"`
+	Schemas               []string              `json:"schemas,omitempty"` // empty when every schema was inventoried
+	Counts                []InventoryCount      `json:"counts"`
+	OnlyInDB1             []DatabaseObject      `json:"only_in_db1"`
+	OnlyInDB2             []DatabaseObject      `json:"only_in_db2"`
+	DefinitionDifferences []InventoryDifference `json:"definition_differences"`
+}
+
+// HasDifferences reports whether the two inventories disagree
+func (r *InventoryComparisonResult) HasDifferences() bool {
+	return len(r.OnlyInDB1) > 0 || len(r.OnlyInDB2) > 0 || len(r.DefinitionDifferences) > 0
+}
+
+// SequenceState is the state of a sequence in one database. ColumnBound is the highest value of
+// the owning column (the lowest for descending sequences); it is nil for unowned sequences and
+// empty tables. NextValue is the value the next nextval() call returns.
+type SequenceState struct {
+	LastValue   *int64   `json:"last_value"` // nil until nextval() is called after creation or setval(seq, n, false)
+	NextValue   int64    `json:"next_value"`
+	IncrementBy int64    `json:"increment_by"`
+	MinValue    int64    `json:"min_value"`
+	MaxValue    int64    `json:"max_value"`
+	ColumnBound *int64   `json:"column_bound,omitempty"`
+	UsedPercent float64  `json:"used_percent"` // share of the sequence range already consumed
+	Issues      []string `json:"issues,omitempty"`
+}
+
+// SequenceInfo describes a sequence of a database and the column that owns it, if any
+type SequenceInfo struct {
+	Schema       string `json:"schema"`
+	SequenceName string `json:"sequence_name"`
+	TableName    string `json:"table_name,omitempty"`
+	ColumnName   string `json:"column_name,omitempty"`
+	Identity     bool   `json:"identity"`
+	SequenceState
+}
+
+// SequenceComparison represents the health of one sequence in both databases. DB1 or DB2 is nil
+// when the sequence only exists in the other database.
+type SequenceComparison struct {
+	Schema       string         `json:"schema"`
+	SequenceName string         `json:"sequence_name"`
+	TableName    string         `json:"table_name,omitempty"`
+	ColumnName   string         `json:"column_name,omitempty"`
+	Identity     bool           `json:"identity"`
+	DB1          *SequenceState `json:"db1"`
+	DB2          *SequenceState `json:"db2"`
+	Differs      bool           `json:"differs"` // next values differ between the databases
+}
+
+// AtRisk reports whether the sequence has issues in either database
+func (s SequenceComparison) AtRisk() bool {
+	return (s.DB1 != nil && len(s.DB1.Issues) > 0) || (s.DB2 != nil && len(s.DB2.Issues) > 0)
+}
+
+// SequenceHealthResult represents the sequence health check of both databases
+type SequenceHealthResult struct {
+	Timestamp       time.Time            `json:"timestamp"`
+	Schemas         []string             `json:"schemas,omitempty"` // empty when every schema was checked
+	TotalSequences  int                  `json:"total_sequences"`
+	AtRiskSequences int                  `json:"at_risk_sequences"`
+	DifferingValues int                  `json:"differing_values"`
+	Sequences       []SequenceComparison `json:"sequences"`
+}
+
+// TableStats holds the size figures of a table in one database
+type TableStats struct {
+	RowCount     int64      `json:"row_count"` // exact or planner estimate, see TableOverviewResult.ExactCounts
+	TotalBytes   int64      `json:"total_bytes"`
+	TotalSize    string     `json:"total_size"`
+	LastAnalyzed *time.Time `json:"last_analyzed"` // nil if never analyzed
+}
+
+// TableInfo is a table of a database with its size figures
+type TableInfo struct {
+	Schema    string `json:"schema"`
+	TableName string `json:"table_name"`
+	TableStats
+}
+
+// TableOverview represents the size figures of a table in both databases side by side.
+// DB1 or DB2 is nil when the table only exists in the other database.
+type TableOverview struct {
+	Schema    string      `json:"schema"`
+	TableName string      `json:"table_name"`
+	DB1       *TableStats `json:"db1"`
+	DB2       *TableStats `json:"db2"`
+	RowDelta  int64       `json:"row_delta"` // DB2 rows minus DB1 rows
+	Diverges  bool        `json:"diverges"`
+}
+
+// TableOverviewResult represents the row-count and size overview of the tables of both databases
+type TableOverviewResult struct {
+	Timestamp       time.Time       `json:"timestamp"`
+	Schemas         []string        `json:"schemas,omitempty"` // empty when every schema was listed
+	ExactCounts     bool            `json:"exact_counts"`      // false when row counts are planner estimates
+	TotalTables     int             `json:"total_tables"`
+	DivergingTables int             `json:"diverging_tables"`
+	Tables          []TableOverview `json:"tables"`
+}
+
+// Outcomes of a table comparison in a batch
+const (
+	BatchStatusIdentical = "identical"
+	BatchStatusDifferent = "different"
+	BatchStatusFailed    = "failed"
+)
+
+// BatchTableSummary summarizes the comparison of one table of a batch
+type BatchTableSummary struct {
+	Schema       string `json:"schema"`
+	TableName    string `json:"table_name"`
+	Status       string `json:"status"` // identical, different or failed
+	Error        string `json:"error,omitempty"`
+	TotalRowsDB1 int    `json:"total_rows_db1"`
+	TotalRowsDB2 int    `json:"total_rows_db2"`
+	MatchedRows  int    `json:"matched_rows"`
+	OnlyInDB1    int    `json:"only_in_db1"`
+	OnlyInDB2    int    `json:"only_in_db2"`
+	Differences  int    `json:"differences"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
+// BatchComparisonResult represents the comparison of several tables in one run: a summary per
+// table in the order the tables were resolved, and the full results of the tables compared
+type BatchComparisonResult struct {
+	Timestamp       time.Time           `json:"timestamp"`
+	TotalTables     int                 `json:"total_tables"`
+	IdenticalTables int                 `json:"identical_tables"`
+	DifferentTables int                 `json:"different_tables"`
+	FailedTables    int                 `json:"failed_tables"`
+	DurationMs      int64               `json:"duration_ms"`
+	Summaries       []BatchTableSummary `json:"summaries"`
+	Results         []*ComparisonResult `json:"results"`
+}
+
+// Outcomes of the comparison of a single entity
+const (
+	EntityStatusIdentical = "identical"
+	EntityStatusDifferent = "different"
+	EntityStatusOnlyInDB1 = "only_in_db1"
+	EntityStatusOnlyInDB2 = "only_in_db2"
+)
+
+// EntityComparisonResult is the focused comparison of one row, found by its key values in both
+// databases, and of its neighbouring rows: the rows it references (Outgoing) and the rows of the
+// tables referencing it (Incoming). Status covers the row itself.
+type EntityComparisonResult struct {
+	Schema       string             `json:"schema"`
+	TableName    string             `json:"table_name"`
+	DB2Schema    string             `json:"db2_schema,omitempty"`
+	DB2TableName string             `json:"db2_table_name,omitempty"`
+	Timestamp    time.Time          `json:"timestamp"`
+	KeyColumns   []string           `json:"key_columns"`
+	KeyValues    []string           `json:"key_values"`
+	Status       string             `json:"status"` // identical, different, only_in_db1 or only_in_db2
+	DB1Row       TableRow           `json:"db1_row,omitempty"`
+	DB2Row       TableRow           `json:"db2_row,omitempty"`
+	Difference   *RowDifference     `json:"difference,omitempty"`
+	Outgoing     []ForeignKeyResult `json:"outgoing"`
+	Incoming     []ChildDifference  `json:"incoming"`
+}
+
+// HasDifferences reports whether the entity or any of its neighbouring rows differ
+func (r *EntityComparisonResult) HasDifferences() bool {
+	if r.Status != EntityStatusIdentical || foreignKeyResultsDiffer(r.Outgoing) {
+		return true
+	}
+	for _, child := range r.Incoming {
+		if len(child.OnlyInDB1) > 0 || len(child.OnlyInDB2) > 0 || len(child.Differences) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// foreignKeyResultsDiffer reports whether foreign key comparisons, nested ones included, found
+// referenced rows that differ or exist in one database only
+func foreignKeyResultsDiffer(results []ForeignKeyResult) bool {
+	for _, fk := range results {
+		comparison := fk.ComparisonResult
+		if fk.Error != "" || len(comparison.OnlyInDB1) > 0 || len(comparison.OnlyInDB2) > 0 ||
+			len(comparison.Differences) > 0 || foreignKeyResultsDiffer(fk.Nested) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignKeyResult represents the result of a foreign key comparison.
+// Nested holds the comparisons of the foreign keys of the referenced table when they are followed
+// further (MatchCriteria.FKDepth). CycleDetected is set when the referenced table is already on the
+// path from the compared table, so its foreign keys are not followed again. NestedElsewhere is set
+// when some referenced rows were already compared, or all their foreign keys already followed as
+// far, through another result; those rows are left out of this one.
 type ForeignKeyResult struct {
 	ForeignKey       ForeignKey            `json:"foreign_key"`
 	ComparisonResult ComparisonResult      `json:"comparison_result"`
 	Error            string                `json:"error,omitempty"`
 	FKReferences     []ForeignKeyReference `json:"fk_references,omitempty"`
+	CycleDetected    bool                  `json:"cycle_detected,omitempty"`
+	NestedElsewhere  bool                  `json:"nested_elsewhere,omitempty"`
+	Nested           []ForeignKeyResult    `json:"nested,omitempty"`
 }
 
-// MatchCriteria represents the criteria used to match rows between tables
+// MatchCriteria represents the criteria used to match rows between tables.
+// When KeyColumns is set, rows are paired on those columns only and Columns narrows the compared columns.
+// WhereDB1 and WhereDB2 are SQL predicates restricting the rows read from each database.
+// DB2Schema and DB2Table name the DB2 table when it differs from DB1, and ColumnMap maps DB1 column
+// names to their DB2 names; results always use the DB1 names.
+// A non-zero ProbableMatchThreshold pairs unmatched rows at least that similar as probable matches.
+// CaseInsensitiveColumns and TrimColumns relax the comparison of text columns ("*" selects all columns).
+// Tolerances maps a column to the amount ("0.01") or duration ("5s") by which its values may differ and
+// still count as equal; ReportWithinTolerance keeps such differences in a separate section.
+// The JSON options control how json/jsonb columns are compared; JSONIgnorePaths holds $.paths,
+// optionally prefixed with "column:" to apply to a single column. Array columns listed in
+// UnorderedArrayColumns ("*" for all) are compared as multisets of elements.
+// NullEquivalents maps a column ("*" for all) to the values that compare equal to NULL:
+// "empty" (”), "zero" (0) or "false".
+// FKDepth is the number of foreign key hops followed from the compared table; 0 and 1 compare only
+// the rows it references directly.
+// Aggregate compares each matched row together with its child rows in the tables referencing its
+// primary key, limited to ChildTables ("table" or "schema.table", globs allowed) when set.
+// OptionalColumns is set when the criteria are shared by many tables: tolerances and NULL equivalents
+// naming a column are then ignored by the tables without that column instead of failing them.
 type MatchCriteria struct {
-	Columns                []string `json:"columns"`
-	ExcludeColumns         []string `json:"exclude_columns"`
-	IncludePrimaryKey      bool     `json:"include_primary_key"`
-	ExcludeColumnsFromFile bool     `json:"exclude_columns_from_file"`
-	ExcludeColumnsFile     string   `json:"exclude_columns_file"`
+	KeyColumns             []string            `json:"key_columns,omitempty"`
+	Columns                []string            `json:"columns"`
+	ExcludeColumns         []string            `json:"exclude_columns"`
+	IncludePrimaryKey      bool                `json:"include_primary_key"`
+	ExcludeColumnsFromFile bool                `json:"exclude_columns_from_file"`
+	ExcludeColumnsFile     string              `json:"exclude_columns_file"`
+	WhereDB1               string              `json:"where_db1,omitempty"`
+	WhereDB2               string              `json:"where_db2,omitempty"`
+	ProbableMatchThreshold float64             `json:"probable_match_threshold,omitempty"`
+	CaseInsensitiveColumns []string            `json:"case_insensitive_columns,omitempty"`
+	TrimColumns            []string            `json:"trim_columns,omitempty"`
+	Tolerances             map[string]string   `json:"tolerances,omitempty"`
+	ReportWithinTolerance  bool                `json:"report_within_tolerance,omitempty"`
+	JSONIgnoreKeyOrder     bool                `json:"json_ignore_key_order,omitempty"`
+	JSONIgnorePaths        []string            `json:"json_ignore_paths,omitempty"`
+	JSONUnorderedArrays    bool                `json:"json_unordered_arrays,omitempty"`
+	UnorderedArrayColumns  []string            `json:"unordered_array_columns,omitempty"`
+	DB2Schema              string              `json:"db2_schema,omitempty"`
+	DB2Table               string              `json:"db2_table,omitempty"`
+	ColumnMap              map[string]string   `json:"column_map,omitempty"`
+	NullEquivalents        map[string][]string `json:"null_equivalents,omitempty"`
+	FKDepth                int                 `json:"fk_depth,omitempty"`
+	Aggregate              bool                `json:"aggregate,omitempty"`
+	ChildTables            []string            `json:"child_tables,omitempty"`
+	OptionalColumns        bool                `json:"optional_columns,omitempty"`
+}
+
+// TableTarget is the table read on one side of a comparison. ColumnMap maps the DB1 column
+// names, used throughout the comparison, to the names of this side.
+type TableTarget struct {
+	Schema    string
+	Table     string
+	Where     string
+	ColumnMap map[string]string
+}
+
+// Targets returns the DB1 and DB2 tables compared for a table of DB1
+func (mc *MatchCriteria) Targets(schema, tableName string) (TableTarget, TableTarget) {
+	target1 := TableTarget{Schema: schema, Table: tableName}
+	target2 := target1
+	if mc == nil {
+		return target1, target2
+	}
+
+	target1.Where = mc.WhereDB1
+	target2.Where = mc.WhereDB2
+	if mc.DB2Schema != "" {
+		target2.Schema = mc.DB2Schema
+	}
+	if mc.DB2Table != "" {
+		target2.Table = mc.DB2Table
+	}
+	target2.ColumnMap = mc.ColumnMap
+
+	return target1, target2
+}
+
+// Column returns the name of a DB1 column in this table
+func (t TableTarget) Column(name string) string {
+	if mapped, exists := t.ColumnMap[name]; exists {
+		return mapped
+	}
+	return name
+}
+
+// ColumnList returns the names of DB1 columns in this table
+func (t TableTarget) ColumnList(names []string) []string {
+	if len(t.ColumnMap) == 0 {
+		return names
+	}
+
+	mapped := make([]string, len(names))
+	for i, name := range names {
+		mapped[i] = t.Column(name)
+	}
+	return mapped
+}
+
+// CanonicalRow renames the columns of a row read from this table to their DB1 names
+func (t TableTarget) CanonicalRow(row TableRow) TableRow {
+	if len(t.ColumnMap) == 0 {
+		return row
+	}
+
+	mappedNames := make(map[string]bool, len(t.ColumnMap))
+	for _, mapped := range t.ColumnMap {
+		mappedNames[mapped] = true
+	}
+
+	renamed := make(TableRow, len(row))
+	for col, value := range row {
+		if !mappedNames[col] {
+			renamed[col] = value
+		}
+	}
+	for canonical, mapped := range t.ColumnMap {
+		if value, exists := row[mapped]; exists {
+			renamed[canonical] = value
+		}
+	}
+	return renamed
+}
+
+// CanonicalRows renames the columns of rows read from this table to their DB1 names
+func (t TableTarget) CanonicalRows(rows []TableRow) []TableRow {
+	if len(t.ColumnMap) == 0 {
+		return rows
+	}
+
+	renamed := make([]TableRow, len(rows))
+	for i, row := range rows {
+		renamed[i] = t.CanonicalRow(row)
+	}
+	return renamed
 }
 
 // LoadExcludeColumnsFromFile loads column names to exclude from a file
@@ -286,40 +925,7 @@ func (u *UUIDDecoder) ProcessComparisonResult(result *ComparisonResult) *Compari
 
 	// Process differences
 	for i, diff := range result.Differences {
-		// Process DB1 row
-		processedDB1Row := make(TableRow)
-		for key, value := range diff.DB1Row {
-			if strValue, ok := value.(string); ok {
-				decodedValue := u.DecodeBase64UUID(strValue)
-
-				processedDB1Row[key] = decodedValue
-			} else {
-				processedDB1Row[key] = value
-			}
-		}
-		result.Differences[i].DB1Row = processedDB1Row
-
-		// Process DB2 row
-		processedDB2Row := make(TableRow)
-		for key, value := range diff.DB2Row {
-			if strValue, ok := value.(string); ok {
-				processedDB2Row[key] = u.DecodeBase64UUID(strValue)
-			} else {
-				processedDB2Row[key] = value
-			}
-		}
-		result.Differences[i].DB2Row = processedDB2Row
-
-		// Process column differences
-		for j, colDiff := range diff.ColumnDifferences {
-
-			if db1StrValue, ok := colDiff.DB1Value.(string); ok {
-				result.Differences[i].ColumnDifferences[j].DB1Value = u.DecodeBase64UUID(db1StrValue)
-			}
-			if db2StrValue, ok := colDiff.DB2Value.(string); ok {
-				result.Differences[i].ColumnDifferences[j].DB2Value = u.DecodeBase64UUID(db2StrValue)
-			}
-		}
+		result.Differences[i] = u.ProcessRowDifference(diff)
 	}
 
 	// Process only in DB1
@@ -348,9 +954,61 @@ func (u *UUIDDecoder) ProcessComparisonResult(result *ComparisonResult) *Compari
 		result.OnlyInDB2[i] = processedRow
 	}
 
+	// Process duplicate keys
+	for i, dup := range result.DuplicateKeys {
+		result.DuplicateKeys[i] = u.ProcessDuplicateKey(dup)
+	}
+
+	// Process differences within tolerance
+	for i, diff := range result.WithinTolerance {
+		result.WithinTolerance[i] = u.ProcessRowDifference(diff)
+	}
+
+	// Process probable matches
+	for i, match := range result.ProbableMatches {
+		result.ProbableMatches[i].RowDifference = u.ProcessRowDifference(match.RowDifference)
+	}
+
 	return result
 }
 
+// ProcessDuplicateKey decodes UUIDs in the rows attached to a duplicate key difference
+func (u *UUIDDecoder) ProcessDuplicateKey(dup DuplicateKeyDifference) DuplicateKeyDifference {
+	if !u.DecodeEnabled {
+		return dup
+	}
+
+	for i, row := range dup.DB1Rows {
+		dup.DB1Rows[i] = u.ProcessTableRow(row)
+	}
+	for i, row := range dup.DB2Rows {
+		dup.DB2Rows[i] = u.ProcessTableRow(row)
+	}
+
+	return dup
+}
+
+// ProcessRowDifference decodes UUIDs in both rows and the column differences of a row difference
+func (u *UUIDDecoder) ProcessRowDifference(diff RowDifference) RowDifference {
+	if !u.DecodeEnabled {
+		return diff
+	}
+
+	diff.DB1Row = u.ProcessTableRow(diff.DB1Row)
+	diff.DB2Row = u.ProcessTableRow(diff.DB2Row)
+
+	for j, colDiff := range diff.ColumnDifferences {
+		if db1StrValue, ok := colDiff.DB1Value.(string); ok {
+			diff.ColumnDifferences[j].DB1Value = u.DecodeBase64UUID(db1StrValue)
+		}
+		if db2StrValue, ok := colDiff.DB2Value.(string); ok {
+			diff.ColumnDifferences[j].DB2Value = u.DecodeBase64UUID(db2StrValue)
+		}
+	}
+
+	return diff
+}
+
 // ProcessMatchReferenceResult processes a match reference result to decode UUIDs
 func (u *UUIDDecoder) ProcessMatchReferenceResult(result *MatchReferenceResult) *MatchReferenceResult {
 	if !u.DecodeEnabled {
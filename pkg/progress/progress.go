@@ -5,9 +5,19 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// quiet suppresses the output of progress bars and simple progress indicators
+var quiet atomic.Bool
+
+// SetQuiet enables or disables the output of progress bars and simple progress indicators,
+// for callers that run several operations at once and report progress themselves
+func SetQuiet(enabled bool) {
+	quiet.Store(enabled)
+}
+
 // ProgressBar represents a progress bar with timing and status information
 type ProgressBar struct {
 	total       int64
@@ -67,6 +77,9 @@ func (pb *ProgressBar) Finish() {
 	pb.current = pb.total
 	pb.completed = true
 	pb.lastUpdate = time.Now()
+	if quiet.Load() {
+		return
+	}
 	pb.render()
 	fmt.Println() // New line after completion
 }
@@ -79,6 +92,9 @@ func (pb *ProgressBar) FinishWithMessage(message string) {
 	pb.current = pb.total
 	pb.completed = true
 	pb.lastUpdate = time.Now()
+	if quiet.Load() {
+		return
+	}
 
 	elapsed := pb.lastUpdate.Sub(pb.startTime)
 	// Clear line and show final completion message
@@ -88,7 +104,7 @@ func (pb *ProgressBar) FinishWithMessage(message string) {
 
 // render draws the progress bar
 func (pb *ProgressBar) render() {
-	if pb.total == 0 {
+	if pb.total == 0 || quiet.Load() {
 		return
 	}
 
@@ -210,6 +226,9 @@ func (sp *SimpleProgress) Update(increment int64) {
 	defer sp.mutex.Unlock()
 
 	sp.current += increment
+	if quiet.Load() {
+		return
+	}
 	elapsed := time.Since(sp.startTime)
 
 	// Clear line and show simple progress
@@ -223,6 +242,10 @@ func (sp *SimpleProgress) Finish(message string) {
 	sp.mutex.Lock()
 	defer sp.mutex.Unlock()
 
+	if quiet.Load() {
+		return
+	}
+
 	elapsed := time.Since(sp.startTime)
 	// Clear line and show final completion message
 	fmt.Printf("\r\033[K%s ✅ %s - %d processed (%v)\n",
@@ -243,7 +266,7 @@ func formatDuration(d time.Duration) string {
 
 // ShowProgress is a utility function for simple progress display
 func ShowProgress(current, total int64, description string) {
-	if total == 0 {
+	if total == 0 || quiet.Load() {
 		return
 	}
 